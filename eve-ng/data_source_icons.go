@@ -2,6 +2,8 @@ package eveng
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -21,30 +23,24 @@ func dataSourceEveIcons() *schema.Resource {
 	}
 }
 
-func dataSourceEveIconsRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func dataSourceEveIconsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
-	resp, err := c.Get("api/list/networks")
+	resp, err := c.GetContext(ctx, "api/icons")
 	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	var result struct {
-		Code    int               `json:"code"`
-		Status  string            `json:"status"`
-		Message string            `json:"message"`
-		Icons   map[string]string `json:"icons"`
+		return diag.FromErr(fmt.Errorf("failed to list icons: %w", err))
 	}
-
-	if err := c.HandleResponse(resp, &result); err != nil {
-		return diag.FromErr(err)
+	result, err := client.DecodeResponse[map[string]string](resp)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list icons: %w", err))
 	}
 
 	// アイコン名のリストを返す（シンプルな文字列配列）
-	iconNames := make([]string, 0, len(result.Icons))
-	for iconName := range result.Icons {
+	iconNames := make([]string, 0, len(result.Data))
+	for iconName := range result.Data {
 		iconNames = append(iconNames, iconName)
 	}
+	sort.Strings(iconNames)
 
 	d.SetId("icons")
 	if err := d.Set("icons", iconNames); err != nil {
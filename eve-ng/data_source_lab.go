@@ -0,0 +1,228 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+func dataSourceEveLab() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceEveLabRead,
+		Schema: map[string]*schema.Schema{
+			"file":        {Type: schema.TypeString, Required: true},
+			"name":        {Type: schema.TypeString, Computed: true},
+			"author":      {Type: schema.TypeString, Computed: true},
+			"description": {Type: schema.TypeString, Computed: true},
+			"version":     {Type: schema.TypeString, Computed: true},
+			"locked":      {Type: schema.TypeBool, Computed: true},
+			"node": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id":       {Type: schema.TypeInt, Computed: true},
+						"name":     {Type: schema.TypeString, Computed: true},
+						"type":     {Type: schema.TypeString, Computed: true},
+						"template": {Type: schema.TypeString, Computed: true},
+						"image":    {Type: schema.TypeString, Computed: true},
+						"status":   {Type: schema.TypeString, Computed: true}, // stopped, building, started or locked
+						"console":  {Type: schema.TypeString, Computed: true},
+						"url":      {Type: schema.TypeString, Computed: true}, // console URL
+					},
+				},
+			},
+			"network": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id":   {Type: schema.TypeInt, Computed: true},
+						"name": {Type: schema.TypeString, Computed: true},
+						"type": {Type: schema.TypeString, Computed: true},
+					},
+				},
+			},
+			// one entry per connected interface; a serial link is listed once
+			"link": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"node_id":          {Type: schema.TypeInt, Computed: true},
+						"node_name":        {Type: schema.TypeString, Computed: true},
+						"interface":        {Type: schema.TypeString, Computed: true},
+						"network_id":       {Type: schema.TypeInt, Computed: true},
+						"network_name":     {Type: schema.TypeString, Computed: true},
+						"remote_node_id":   {Type: schema.TypeInt, Computed: true},
+						"remote_node_name": {Type: schema.TypeString, Computed: true},
+						"remote_interface": {Type: schema.TypeString, Computed: true},
+					},
+				},
+			},
+			// IDs keyed by name, for referencing devices by name
+			"node_ids":    {Type: schema.TypeMap, Computed: true, Elem: &schema.Schema{Type: schema.TypeInt}},
+			"network_ids": {Type: schema.TypeMap, Computed: true, Elem: &schema.Schema{Type: schema.TypeInt}},
+		},
+	}
+}
+
+// labInventory is a lab with its nodes, networks and node interfaces
+type labInventory struct {
+	lab      labData
+	nodes    indexedList[map[string]interface{}]
+	networks indexedList[networkData]
+	ifaces   map[int]*nodeInterfacesData
+}
+
+func readLabInventory(ctx context.Context, c *client.Client, labFile string) (*labInventory, error) {
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lab %s: %w", labFile, err)
+	}
+	lab, err := client.DecodeResponse[labData](resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lab %s: %w", labFile, err)
+	}
+
+	resp, err = c.GetContext(ctx, "api/labs"+labFile+"/nodes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	nodes, err := client.DecodeResponse[indexedList[map[string]interface{}]](resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	resp, err = c.GetContext(ctx, "api/labs"+labFile+"/networks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+	networks, err := client.DecodeResponse[indexedList[networkData]](resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	inv := &labInventory{lab: lab.Data, nodes: nodes.Data, networks: networks.Data, ifaces: map[int]*nodeInterfacesData{}}
+	for id := range inv.nodes {
+		if inv.ifaces[id], err = getNodeInterfaces(ctx, c, labFile, id); err != nil {
+			return nil, fmt.Errorf("failed to read interfaces of node %d: %w", id, err)
+		}
+	}
+	return inv, nil
+}
+
+func (inv *labInventory) flattenNodes() ([]interface{}, map[string]interface{}) {
+	nodes := make([]interface{}, 0, len(inv.nodes))
+	ids := map[string]interface{}{}
+	for _, id := range sortedKeys(inv.nodes) {
+		data := inv.nodes[id]
+		nodes = append(nodes, map[string]interface{}{
+			"id":       id,
+			"name":     liveString(data, "name"),
+			"type":     liveString(data, "type"),
+			"template": liveString(data, "template"),
+			"image":    liveString(data, "image"),
+			"status":   nodeStates[liveInt(data, "status")],
+			"console":  liveString(data, "console"),
+			"url":      liveString(data, "url"),
+		})
+		if _, dup := ids[liveString(data, "name")]; !dup {
+			ids[liveString(data, "name")] = id
+		}
+	}
+	return nodes, ids
+}
+
+func (inv *labInventory) flattenNetworks() ([]interface{}, map[string]interface{}) {
+	networks := make([]interface{}, 0, len(inv.networks))
+	ids := map[string]interface{}{}
+	for _, id := range sortedKeys(inv.networks) {
+		network := inv.networks[id]
+		networks = append(networks, map[string]interface{}{"id": id, "name": network.Name, "type": network.Type})
+		if _, dup := ids[network.Name]; !dup {
+			ids[network.Name] = id
+		}
+	}
+	return networks, ids
+}
+
+func (inv *labInventory) flattenLinks() []interface{} {
+	var links []interface{}
+	for _, id := range sortedKeys(inv.nodes) {
+		ifaces := inv.ifaces[id]
+		name := liveString(inv.nodes[id], "name")
+		for _, idx := range sortedKeys(ifaces.Ethernet) {
+			eth := ifaces.Ethernet[idx]
+			if eth.NetworkID == 0 {
+				continue
+			}
+			links = append(links, map[string]interface{}{
+				"node_id": id, "node_name": name, "interface": eth.Name,
+				"network_id": eth.NetworkID, "network_name": inv.networks[eth.NetworkID].Name,
+			})
+		}
+		for _, idx := range sortedKeys(ifaces.Serial) {
+			if link, ok := inv.serialLink(id, idx); ok {
+				links = append(links, link)
+			}
+		}
+	}
+	return links
+}
+
+// serialLink returns the link of a connected serial interface, unless it is
+// listed from its remote end
+func (inv *labInventory) serialLink(nodeID, idx int) (map[string]interface{}, bool) {
+	serial := inv.ifaces[nodeID].Serial[idx]
+	if serial.RemoteID == nil || *serial.RemoteID == 0 {
+		return nil, false
+	}
+	remoteID := *serial.RemoteID
+	var remoteIf string
+	if remote, ok := inv.ifaces[remoteID]; ok && serial.RemoteIf != nil {
+		back := remote.Serial[*serial.RemoteIf]
+		if back.RemoteID != nil && *back.RemoteID == nodeID && (remoteID < nodeID || (remoteID == nodeID && *serial.RemoteIf < idx)) {
+			return nil, false
+		}
+		remoteIf = back.Name
+	}
+	return map[string]interface{}{
+		"node_id": nodeID, "node_name": liveString(inv.nodes[nodeID], "name"), "interface": serial.Name,
+		"remote_node_id": remoteID, "remote_node_name": liveString(inv.nodes[remoteID], "name"), "remote_interface": remoteIf,
+	}, true
+}
+
+func dataSourceEveLabRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := d.Get("file").(string)
+
+	inv, err := readLabInventory(ctx, c, labFile)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	nodes, nodeIDs := inv.flattenNodes()
+	networks, networkIDs := inv.flattenNetworks()
+
+	d.SetId(labFile)
+	_ = d.Set("name", inv.lab.Name)
+	_ = d.Set("author", inv.lab.Author)
+	_ = d.Set("description", inv.lab.Description)
+	_ = d.Set("version", handleVersionField(inv.lab.Version))
+	_ = d.Set("locked", handleLockField(inv.lab.Lock))
+	_ = d.Set("node_ids", nodeIDs)
+	_ = d.Set("network_ids", networkIDs)
+	if err := d.Set("node", nodes); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("network", networks); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("link", inv.flattenLinks()); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+func dataSourceEveLabs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceEveLabsRead,
+		Schema: map[string]*schema.Schema{
+			"path":       {Type: schema.TypeString, Optional: true, Default: "/"},
+			"recursive":  {Type: schema.TypeBool, Optional: true, Default: false},
+			"name_regex": {Type: schema.TypeString, Optional: true, ValidateFunc: validation.StringIsValidRegExp},
+			"labs": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"file":        {Type: schema.TypeString, Computed: true},
+						"folder":      {Type: schema.TypeString, Computed: true},
+						"name":        {Type: schema.TypeString, Computed: true},
+						"author":      {Type: schema.TypeString, Computed: true},
+						"description": {Type: schema.TypeString, Computed: true},
+						"locked":      {Type: schema.TypeBool, Computed: true},
+						"mtime":       {Type: schema.TypeString, Computed: true},
+						"umtime":      {Type: schema.TypeInt, Computed: true}, // Unix time of mtime
+					},
+				},
+			},
+		},
+	}
+}
+
+// listFolder returns the subfolders and labs of a folder
+func listFolder(ctx context.Context, c *client.Client, path string) (*folderListData, error) {
+	apiPath := strings.TrimSuffix(path, "/")
+	resp, err := c.GetContext(ctx, "api/folders"+apiPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folder %s: %w", path, err)
+	}
+	result, err := client.DecodeResponse[folderListData](resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folder %s: %w", path, err)
+	}
+	return &result.Data, nil
+}
+
+// walkLabs returns the labs in a folder and, if recursive, in its subfolders
+func walkLabs(ctx context.Context, c *client.Client, path string, recursive bool) ([]folderLab, error) {
+	var labs []folderLab
+	pending := []string{path}
+	seen := map[string]bool{}
+	for len(pending) > 0 {
+		folder := pending[0]
+		pending = pending[1:]
+		if seen[folder] {
+			continue
+		}
+		seen[folder] = true
+
+		data, err := listFolder(ctx, c, folder)
+		if err != nil {
+			return nil, err
+		}
+		labs = append(labs, data.Labs...)
+		if !recursive {
+			continue
+		}
+		for _, sub := range data.Folders {
+			// EVE-NG lists the parent folder as ".."
+			if sub.Name != ".." {
+				pending = append(pending, sub.Path)
+			}
+		}
+	}
+	sort.Slice(labs, func(i, j int) bool { return labs[i].Path < labs[j].Path })
+	return labs, nil
+}
+
+// labFolder returns the folder a lab file is stored in
+func labFolder(labFile string) string {
+	if i := strings.LastIndex(labFile, "/"); i > 0 {
+		return labFile[:i]
+	}
+	return "/"
+}
+
+func dataSourceEveLabsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	path := normalizePath(d.Get("path").(string))
+	recursive := d.Get("recursive").(bool)
+
+	var nameRegex *regexp.Regexp
+	if v := d.Get("name_regex").(string); v != "" {
+		nameRegex = regexp.MustCompile(v)
+	}
+
+	found, err := walkLabs(ctx, c, path, recursive)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	labs := make([]map[string]interface{}, 0, len(found))
+	for _, lab := range found {
+		// Match the lab file name so that only the matching labs are fetched
+		if nameRegex != nil && !nameRegex.MatchString(labFileName(lab.Path)) {
+			continue
+		}
+		resp, err := c.GetContext(ctx, "api/labs"+lab.Path)
+		var result *client.Response[labData]
+		if err == nil {
+			result, err = client.DecodeResponse[labData](resp)
+		}
+		if err != nil {
+			// The lab may have been deleted since the folder was listed
+			if client.IsNotFound(err) {
+				log.Printf("[WARN] Lab %s disappeared while listing labs", lab.Path)
+				continue
+			}
+			return diag.FromErr(fmt.Errorf("failed to get lab %s: %w", lab.Path, err))
+		}
+		labs = append(labs, map[string]interface{}{
+			"file":        lab.Path,
+			"folder":      labFolder(lab.Path),
+			"name":        result.Data.Name,
+			"author":      result.Data.Author,
+			"description": result.Data.Description,
+			"locked":      handleLockField(result.Data.Lock),
+			"mtime":       lab.Mtime,
+			"umtime":      int(lab.Umtime),
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%s:%t:%s", path, recursive, d.Get("name_regex")))
+	if err := d.Set("labs", labs); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -29,22 +29,16 @@ func dataSourceEveNetworkTypes() *schema.Resource {
 	}
 }
 
-func dataSourceEveNetworkTypesRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func dataSourceEveNetworkTypesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
-	resp, err := c.Get("api/list/networks")
+	resp, err := c.GetContext(ctx, "api/list/networks")
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int                    `json:"code"`
-		Status  string                 `json:"status"`
-		Message string                 `json:"message"`
-		Data    map[string]interface{} `json:"data"`
-	}
-
-	if err := c.HandleResponse(resp, &result); err != nil {
+	result, err := client.DecodeResponse[map[string]interface{}](resp)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -0,0 +1,63 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// networkListFields are the eve_network attributes returned by eve_networks
+var networkListFields = []string{"lab_file", "id", "name", "type", "top", "left", "icon", "visibility", "node_count"}
+
+var networkListElem = &schema.Resource{Schema: computedSchema(networkSchema(), networkListFields)}
+
+func dataSourceEveNetworks() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceEveNetworksRead,
+		Schema: map[string]*schema.Schema{
+			"lab_file":   {Type: schema.TypeString, Required: true},
+			"name_regex": {Type: schema.TypeString, Optional: true, ValidateFunc: validation.StringIsValidRegExp},
+			"type":       {Type: schema.TypeString, Optional: true}, // e.g. bridge or pnet0
+			"icon":       {Type: schema.TypeString, Optional: true},
+			"networks": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     networkListElem,
+			},
+		},
+	}
+}
+
+func dataSourceEveNetworksRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := d.Get("lab_file").(string)
+
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/networks")
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list networks: %w", err))
+	}
+	result, err := client.DecodeResponse[indexedList[networkData]](resp)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list networks: %w", err))
+	}
+
+	filter := newListFilter(d, map[string]string{"type": "type", "icon": "icon"})
+	networks := make([]interface{}, 0, len(result.Data))
+	for _, id := range sortedKeys(result.Data) {
+		data := result.Data[id]
+		network := flattenWith(networkListElem, func(nd *schema.ResourceData) { setNetworkData(nd, labFile, id, &data, "list") })
+		if filter.match(network) {
+			networks = append(networks, network)
+		}
+	}
+
+	d.SetId(labFile + ":networks")
+	if err := d.Set("networks", networks); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+func dataSourceEveNodeConfigs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceEveNodeConfigsRead,
+		Schema: map[string]*schema.Schema{
+			"lab_file": {Type: schema.TypeString, Required: true},
+			// numeric node IDs or eve_node IDs (<lab_file>:node:<id>); all nodes when empty
+			"node_ids": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"configs": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// labNodeSummary is a node as listed by GET /api/labs/<lab_file>/nodes
+type labNodeSummary struct {
+	Name string `json:"name"`
+}
+
+// listLabNodes returns the nodes of a lab keyed by ID
+func listLabNodes(ctx context.Context, c *client.Client, labFile string) (map[int]labNodeSummary, error) {
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/nodes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	result, err := client.DecodeResponse[indexedList[labNodeSummary]](resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return result.Data, nil
+}
+
+// parseNodeRef accepts a numeric node ID or an eve_node ID in the given lab
+func parseNodeRef(ref, labFile string) (int, error) {
+	if lab, id, ok := parseNodeID(ref); ok {
+		if lab != labFile {
+			return 0, fmt.Errorf("node %q belongs to lab %s, not %s", ref, lab, labFile)
+		}
+		return id, nil
+	}
+	id, err := strconv.Atoi(ref)
+	if err != nil {
+		return 0, fmt.Errorf("invalid node ID %q", ref)
+	}
+	return id, nil
+}
+
+func dataSourceEveNodeConfigsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := d.Get("lab_file").(string)
+
+	nodes, err := listLabNodes(ctx, c, labFile)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var ids []int
+	if refs := d.Get("node_ids").([]interface{}); len(refs) > 0 {
+		for _, ref := range refs {
+			id, err := parseNodeRef(ref.(string), labFile)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if _, ok := nodes[id]; !ok {
+				return diag.Errorf("node %d not found in lab %s", id, labFile)
+			}
+			ids = append(ids, id)
+		}
+	} else {
+		for id := range nodes {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+	}
+
+	configs := make(map[string]interface{}, len(ids))
+	for _, id := range ids {
+		config, err := readStartupConfig(ctx, c, labFile, id)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("node %d: %w", id, err))
+		}
+		name := nodes[id].Name
+		if _, dup := configs[name]; dup {
+			return diag.Errorf("more than one node in lab %s is named %q", labFile, name)
+		}
+		configs[name] = config
+	}
+
+	log.Printf("[DEBUG] Read configs of %d nodes from lab '%s'", len(configs), labFile)
+	d.SetId(labFile + ":configs")
+	if err := d.Set("configs", configs); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// nodeListFields are the eve_node attributes returned by eve_nodes
+var nodeListFields = []string{
+	"id", "name", "type", "template", "image", "icon", "console", "top", "left", "delay",
+	"cpu", "ram", "ethernet", "serial", "uuid", "firstmac", "qemu_version", "qemu_arch", "qemu_nic", "qemu_options",
+	"timos_line", "timos_license", "management_address", "current_state",
+}
+
+var nodeListElem = &schema.Resource{Schema: computedSchema(nodeSchema(), nodeListFields)}
+
+func dataSourceEveNodes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceEveNodesRead,
+		Schema: map[string]*schema.Schema{
+			"lab_file":   {Type: schema.TypeString, Required: true},
+			"name_regex": {Type: schema.TypeString, Optional: true, ValidateFunc: validation.StringIsValidRegExp},
+			"template":   {Type: schema.TypeString, Optional: true},
+			"type":       {Type: schema.TypeString, Optional: true},
+			"status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{nodeStatusStopped, nodeStatusBuilding, nodeStatusStarted, nodeStatusLocked}, false),
+			},
+			"icon": {Type: schema.TypeString, Optional: true},
+			"nodes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     nodeListElem,
+			},
+		},
+	}
+}
+
+// computedSchema returns the given attributes of a resource schema as
+// computed-only attributes, for data sources listing that resource
+func computedSchema(src map[string]*schema.Schema, keys []string) map[string]*schema.Schema {
+	s := make(map[string]*schema.Schema, len(keys))
+	for _, k := range keys {
+		s[k] = &schema.Schema{Type: src[k].Type, Computed: true}
+	}
+	return s
+}
+
+// flattenWith maps an API object to a list element using the setter of the
+// matching resource, so data sources and resources name attributes alike
+func flattenWith(elem *schema.Resource, set func(d *schema.ResourceData)) map[string]interface{} {
+	d := elem.Data(nil)
+	set(d)
+	flat := make(map[string]interface{}, len(elem.Schema))
+	for k := range elem.Schema {
+		flat[k] = d.Get(k)
+	}
+	return flat
+}
+
+// listFilter holds the name regex and exact-match filters of a data source
+type listFilter struct {
+	nameRegex *regexp.Regexp
+	// attribute name to required value, for filters that are set
+	equal map[string]string
+}
+
+// newListFilter reads the name_regex filter and the given exact-match
+// filters, mapped to the attribute they compare with
+func newListFilter(d *schema.ResourceData, filters map[string]string) listFilter {
+	f := listFilter{equal: map[string]string{}}
+	if v := d.Get("name_regex").(string); v != "" {
+		f.nameRegex = regexp.MustCompile(v)
+	}
+	for filter, attr := range filters {
+		if v := d.Get(filter).(string); v != "" {
+			f.equal[attr] = v
+		}
+	}
+	return f
+}
+
+func (f listFilter) match(item map[string]interface{}) bool {
+	if f.nameRegex != nil && !f.nameRegex.MatchString(fmt.Sprint(item["name"])) {
+		return false
+	}
+	for attr, want := range f.equal {
+		if fmt.Sprint(item[attr]) != want {
+			return false
+		}
+	}
+	return true
+}
+
+func dataSourceEveNodesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := d.Get("lab_file").(string)
+
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/nodes")
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list nodes: %w", err))
+	}
+	result, err := client.DecodeResponse[indexedList[map[string]interface{}]](resp)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list nodes: %w", err))
+	}
+
+	filter := newListFilter(d, map[string]string{"template": "template", "type": "type", "status": "current_state", "icon": "icon"})
+	nodes := make([]interface{}, 0, len(result.Data))
+	for _, id := range sortedKeys(result.Data) {
+		node := flattenWith(nodeListElem, func(nd *schema.ResourceData) { setNodeDataFromResponse(nd, id, result.Data[id]) })
+		if filter.match(node) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	d.SetId(labFile + ":nodes")
+	if err := d.Set("nodes", nodes); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
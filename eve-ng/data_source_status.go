@@ -24,43 +24,37 @@ func dataSourceEveStatus() *schema.Resource {
 	}
 }
 
-func dataSourceEveStatusRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func dataSourceEveStatusRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
-	resp, err := c.Get("api/status")
+	resp, err := c.GetContext(ctx, "api/status")
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			CPU struct {
-				Usage float64 `json:"usage"`
-			} `json:"cpu"`
-			Memory struct {
-				Usage float64 `json:"usage"`
-			} `json:"memory"`
-			Disk struct {
-				Usage float64 `json:"usage"`
-			} `json:"disk"`
-			Swap struct {
-				Usage float64 `json:"usage"`
-			} `json:"swap"`
-			RunningWrappers int `json:"running_wrappers"`
-			KSM             struct {
-				Enabled bool `json:"enabled"`
-			} `json:"ksm"`
-			UKSM struct {
-				Enabled bool `json:"enabled"`
-			} `json:"uksm"`
-			CPULimit int `json:"cpu_limit"`
-		} `json:"data"`
-	}
-
-	if err := c.HandleResponse(resp, &result); err != nil {
+	result, err := client.DecodeResponse[struct {
+		CPU struct {
+			Usage float64 `json:"usage"`
+		} `json:"cpu"`
+		Memory struct {
+			Usage float64 `json:"usage"`
+		} `json:"memory"`
+		Disk struct {
+			Usage float64 `json:"usage"`
+		} `json:"disk"`
+		Swap struct {
+			Usage float64 `json:"usage"`
+		} `json:"swap"`
+		RunningWrappers int `json:"running_wrappers"`
+		KSM             struct {
+			Enabled bool `json:"enabled"`
+		} `json:"ksm"`
+		UKSM struct {
+			Enabled bool `json:"enabled"`
+		} `json:"uksm"`
+		CPULimit int `json:"cpu_limit"`
+	}](resp)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
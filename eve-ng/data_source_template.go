@@ -0,0 +1,96 @@
+package eveng
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceEveTemplate() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceEveTemplateRead,
+		Schema: map[string]*schema.Schema{
+			"name":        {Type: schema.TypeString, Required: true},
+			"type":        {Type: schema.TypeString, Computed: true},
+			"description": {Type: schema.TypeString, Computed: true},
+			// installed images in version order; latest_image is the last one
+			"images":        {Type: schema.TypeList, Computed: true, Elem: &schema.Schema{Type: schema.TypeString}},
+			"default_image": {Type: schema.TypeString, Computed: true},
+			"latest_image":  {Type: schema.TypeString, Computed: true},
+			"nic_models":    {Type: schema.TypeList, Computed: true, Elem: &schema.Schema{Type: schema.TypeString}},
+			"console_types": {Type: schema.TypeList, Computed: true, Elem: &schema.Schema{Type: schema.TypeString}},
+			"icon":          {Type: schema.TypeString, Computed: true},
+			"ethernet":      {Type: schema.TypeInt, Computed: true},
+			"serial":        {Type: schema.TypeInt, Computed: true},
+			"cpu":           {Type: schema.TypeInt, Computed: true},
+			"ram":           {Type: schema.TypeInt, Computed: true},
+			// every option of the template, keyed by node attribute
+			"options": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key":    {Type: schema.TypeString, Computed: true},
+						"name":   {Type: schema.TypeString, Computed: true},
+						"type":   {Type: schema.TypeString, Computed: true},
+						"value":  {Type: schema.TypeString, Computed: true},
+						"values": {Type: schema.TypeList, Computed: true, Elem: &schema.Schema{Type: schema.TypeString}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceEveTemplateRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := m.(*providerMeta)
+	name := d.Get("name").(string)
+
+	details, err := meta.templates.template(ctx, meta.client, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	images := details.images()
+	var latest string
+	if len(images) > 0 {
+		latest = images[len(images)-1]
+	}
+
+	keys := make([]string, 0, len(details.Options))
+	for k := range details.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	options := make([]map[string]interface{}, 0, len(keys))
+	for _, k := range keys {
+		opt := details.Options[k]
+		options = append(options, map[string]interface{}{
+			"key":    k,
+			"name":   opt.Name,
+			"type":   opt.Type,
+			"value":  details.stringOption(k),
+			"values": opt.List.values(),
+		})
+	}
+
+	d.SetId(name)
+	_ = d.Set("type", details.Type)
+	_ = d.Set("description", details.Description)
+	_ = d.Set("images", images)
+	_ = d.Set("default_image", details.stringOption("image"))
+	_ = d.Set("latest_image", latest)
+	_ = d.Set("nic_models", details.Options["qemu_nic"].List.values())
+	_ = d.Set("console_types", details.Options["console"].List.values())
+	_ = d.Set("icon", details.stringOption("icon"))
+	_ = d.Set("ethernet", details.intOption("ethernet"))
+	_ = d.Set("serial", details.intOption("serial"))
+	_ = d.Set("cpu", details.intOption("cpu"))
+	_ = d.Set("ram", details.intOption("ram"))
+	if err := d.Set("options", options); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
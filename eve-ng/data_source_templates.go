@@ -34,22 +34,16 @@ func dataSourceEveTemplates() *schema.Resource {
 	}
 }
 
-func dataSourceEveTemplatesRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func dataSourceEveTemplatesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
-	resp, err := c.Get("api/list/templates/")
+	resp, err := c.GetContext(ctx, "api/list/templates/")
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int                    `json:"code"`
-		Status  string                 `json:"status"`
-		Message string                 `json:"message"`
-		Data    map[string]interface{} `json:"data"`
-	}
-
-	if err := c.HandleResponse(resp, &result); err != nil {
+	result, err := client.DecodeResponse[map[string]interface{}](resp)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -0,0 +1,30 @@
+package eveng
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// handleReadError removes the resource from state when the object was deleted
+// outside of Terraform and reports any other failure as an error
+func handleReadError(d *schema.ResourceData, err error, what string) diag.Diagnostics {
+	if client.IsNotFound(err) {
+		log.Printf("[WARN] %s not found, removing from state", what)
+		d.SetId("")
+		return nil
+	}
+	log.Printf("[ERROR] Failed to read %s: %v", what, err)
+	return diag.FromErr(fmt.Errorf("failed to read %s: %w", what, err))
+}
+
+// ignoreNotFound treats a missing object as already deleted
+func ignoreNotFound(err error) error {
+	if client.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,302 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// GenerateConfig writes Terraform configuration for an existing lab: an
+// eve_lab, its eve_network and eve_node resources, an eve_link for every hidden
+// bridge joining two interfaces, an eve_interface_attachment for every other
+// connected interface, and import blocks that adopt them all.
+func GenerateConfig(ctx context.Context, c *client.Client, labFile string, w io.Writer) error {
+	inv, err := readLabInventory(ctx, c, labFile)
+	if err != nil {
+		return err
+	}
+
+	g := &generator{inv: inv, labFile: labFile, names: map[string]bool{}, nodeNames: map[int]string{}, networkNames: map[int]string{}}
+	g.findLinks()
+	g.lab()
+	for _, id := range sortedKeys(inv.networks) {
+		if _, ok := g.links[id]; !ok {
+			g.network(id)
+		}
+	}
+	for _, id := range sortedKeys(inv.nodes) {
+		g.node(id)
+	}
+	for _, id := range sortedKeys(g.links) {
+		g.link(id)
+	}
+	for _, id := range sortedKeys(inv.nodes) {
+		g.attachments(id)
+	}
+	for _, imp := range g.imports {
+		g.block(fmt.Sprintf("import {\n  to = %s\n  id = %s\n}\n", imp[0], hclString(imp[1])))
+	}
+
+	_, err = io.WriteString(w, g.out.String())
+	return err
+}
+
+// generator accumulates the generated configuration
+type generator struct {
+	inv     *labInventory
+	labFile string
+	out     strings.Builder
+	// imports are the resource addresses and IDs to import
+	imports [][2]string
+	// names are the resource names used so far, by type
+	names        map[string]bool
+	labName      string
+	nodeNames    map[int]string
+	networkNames map[int]string
+	// links are the ends, node ID and interface index, of the hidden bridge
+	// networks joining exactly two ethernet interfaces
+	links map[int][][2]int
+}
+
+// hclAttr is an attribute of a generated block; expr is written as is
+type hclAttr struct {
+	name string
+	expr string
+}
+
+// resourceName returns a unique resource name of the given type for a
+// server-side name
+func (g *generator) resourceName(typ, name string) string {
+	base := strings.Trim(linkNetworkNameInvalid.ReplaceAllString(name, "_"), "_")
+	if base == "" || (base[0] >= '0' && base[0] <= '9') || base[0] == '-' {
+		base = strings.TrimPrefix(typ, "eve_") + "_" + base
+	}
+	unique := base
+	for i := 2; g.names[typ+"."+unique]; i++ {
+		unique = base + "_" + strconv.Itoa(i)
+	}
+	g.names[typ+"."+unique] = true
+	return unique
+}
+
+func (g *generator) block(s string) {
+	if g.out.Len() > 0 {
+		g.out.WriteString("\n")
+	}
+	g.out.WriteString(s)
+}
+
+// resource writes a resource block with aligned attributes and records its import
+func (g *generator) resource(typ, name, importID string, attrs []hclAttr) {
+	width := 0
+	for _, a := range attrs {
+		if len(a.name) > width {
+			width = len(a.name)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "resource %q %q {\n", typ, name)
+	for _, a := range attrs {
+		fmt.Fprintf(&b, "  %-*s = %s\n", width, a.name, a.expr)
+	}
+	b.WriteString("}\n")
+	g.block(b.String())
+	g.imports = append(g.imports, [2]string{typ + "." + name, importID})
+}
+
+func (g *generator) labFileRef() string {
+	return "eve_lab." + g.labName + ".file"
+}
+
+func (g *generator) lab() {
+	lab := g.inv.lab
+	g.labName = g.resourceName("eve_lab", lab.Name)
+	attrs := []hclAttr{
+		{"path", hclString(labFolder(g.labFile))},
+		{"name", hclString(labFileName(g.labFile))},
+	}
+	attrs = appendString(attrs, "author", lab.Author)
+	attrs = appendString(attrs, "description", lab.Description)
+	attrs = appendString(attrs, "body", lab.Body)
+	if version := handleVersionField(lab.Version); version != "1" {
+		attrs = append(attrs, hclAttr{"version", hclString(version)})
+	}
+	if lab.ScriptTimeout != 0 && lab.ScriptTimeout != 300 {
+		attrs = append(attrs, hclAttr{"scripttimeout", strconv.Itoa(lab.ScriptTimeout)})
+	}
+	if handleLockField(lab.Lock) {
+		attrs = append(attrs, hclAttr{"lock", "true"})
+	}
+	g.resource("eve_lab", g.labName, g.labFile, attrs)
+}
+
+func (g *generator) network(id int) {
+	network := g.inv.networks[id]
+	name := g.resourceName("eve_network", network.Name)
+	g.networkNames[id] = name
+	attrs := []hclAttr{
+		{"lab_file", g.labFileRef()},
+		{"name", hclString(network.Name)},
+		{"type", hclString(network.Type)},
+	}
+	attrs = appendInt(attrs, "top", network.Top)
+	attrs = appendInt(attrs, "left", network.Left)
+	attrs = appendString(attrs, "icon", network.Icon)
+	if visibility := convertVisibilityToString(network.Visibility); visibility != "1" {
+		attrs = append(attrs, hclAttr{"visibility", hclString(visibility)})
+	}
+	g.resource("eve_network", name, g.labFile+":network:"+strconv.Itoa(id), attrs)
+}
+
+// nodeGeneratedStrings and nodeGeneratedInts are the node settings written
+// when the server reports them
+var (
+	nodeGeneratedStrings = []string{"image", "icon", "console", "uuid", "firstmac", "qemu_version", "qemu_arch", "qemu_nic", "qemu_options"}
+	nodeGeneratedInts    = []string{"top", "left", "delay", "cpu", "ram", "ethernet", "serial"}
+)
+
+// findLinks finds the hidden bridge networks that eve_link manages
+func (g *generator) findLinks() {
+	ends := map[int][][2]int{}
+	for _, nodeID := range sortedKeys(g.inv.nodes) {
+		ifaces := g.inv.ifaces[nodeID]
+		for _, idx := range sortedKeys(ifaces.Ethernet) {
+			if netID := ifaces.Ethernet[idx].NetworkID; netID != 0 {
+				ends[netID] = append(ends[netID], [2]int{nodeID, idx})
+			}
+		}
+	}
+	g.links = map[int][][2]int{}
+	for id, network := range g.inv.networks {
+		if network.Type == "bridge" && convertVisibilityToString(network.Visibility) == "0" && len(ends[id]) == 2 {
+			g.links[id] = ends[id]
+		}
+	}
+}
+
+func (g *generator) link(id int) {
+	var attrs []hclAttr
+	var names []string
+	for i, end := range linkEnds {
+		nodeID, idx := g.links[id][i][0], g.links[id][i][1]
+		ifName := g.inv.ifaces[nodeID].Ethernet[idx].Name
+		names = append(names, g.nodeNames[nodeID]+"_"+ifName)
+		attrs = append(attrs,
+			hclAttr{end + "_node_id", nodeIDRef(g.nodeNames[nodeID])},
+			hclAttr{end + "_interface", hclString(ifName)})
+	}
+	attrs = append([]hclAttr{{"lab_file", g.labFileRef()}}, attrs...)
+	attrs = append(attrs, hclAttr{"network_name", hclString(g.inv.networks[id].Name)})
+	g.resource("eve_link", g.resourceName("eve_link", strings.Join(names, "_")), makeLinkID(g.labFile, id), attrs)
+}
+
+func (g *generator) node(id int) {
+	data := g.inv.nodes[id]
+	name := g.resourceName("eve_node", liveString(data, "name"))
+	g.nodeNames[id] = name
+	attrs := []hclAttr{
+		{"lab_file", g.labFileRef()},
+		{"name", hclString(liveString(data, "name"))},
+		{"type", hclString(liveString(data, "type"))},
+		{"template", hclString(liveString(data, "template"))},
+	}
+	for _, k := range nodeGeneratedStrings {
+		attrs = appendString(attrs, k, liveString(data, k))
+	}
+	for _, k := range nodeGeneratedInts {
+		attrs = appendInt(attrs, k, liveInt(data, k))
+	}
+	if powerStateDrifted(nodeStatusStopped, nodeStates[liveInt(data, "status")]) {
+		attrs = append(attrs, hclAttr{"desired_state", hclString(nodeStatusStarted)})
+	}
+	g.resource("eve_node", name, g.labFile+":node:"+strconv.Itoa(id), attrs)
+}
+
+func (g *generator) attachments(nodeID int) {
+	ifaces := g.inv.ifaces[nodeID]
+	node := g.nodeNames[nodeID]
+	for _, idx := range sortedKeys(ifaces.Ethernet) {
+		eth := ifaces.Ethernet[idx]
+		// Links and unconnected interfaces have no attachment
+		network, ok := g.networkNames[eth.NetworkID]
+		if !ok {
+			continue
+		}
+		g.resource("eve_interface_attachment", g.resourceName("eve_interface_attachment", node+"_"+eth.Name), makeIfAttachID(g.labFile, nodeID, idx), []hclAttr{
+			{"lab_file", g.labFileRef()},
+			{"node_id", nodeIDRef(node)},
+			{"interface_name", hclString(eth.Name)},
+			{"target", `"network:${tonumber(split(":network:", eve_network.` + network + `.id)[1])}"`},
+		})
+	}
+	for _, idx := range sortedKeys(ifaces.Serial) {
+		link, ok := g.inv.serialLink(nodeID, idx)
+		if !ok || link["remote_interface"] == "" {
+			continue
+		}
+		remote, ok := g.nodeNames[link["remote_node_id"].(int)]
+		if !ok {
+			continue
+		}
+		g.resource("eve_interface_attachment", g.resourceName("eve_interface_attachment", node+"_"+ifaces.Serial[idx].Name), makeIfAttachID(g.labFile, nodeID, idx), []hclAttr{
+			{"lab_file", g.labFileRef()},
+			{"node_id", nodeIDRef(node)},
+			{"interface_name", hclString(ifaces.Serial[idx].Name)},
+			{"remote_node_id", nodeIDRef(remote)},
+			{"remote_interface", hclString(link["remote_interface"].(string))},
+		})
+	}
+}
+
+// nodeIDRef is the numeric ID of a generated node; the id attribute of
+// eve_node is <lab_file>:node:<id>
+func nodeIDRef(node string) string {
+	return `tonumber(split(":node:", eve_node.` + node + `.id)[1])`
+}
+
+func appendString(attrs []hclAttr, name, value string) []hclAttr {
+	if value == "" {
+		return attrs
+	}
+	return append(attrs, hclAttr{name, hclString(value)})
+}
+
+func appendInt(attrs []hclAttr, name string, value int) []hclAttr {
+	if value == 0 {
+		return attrs
+	}
+	return append(attrs, hclAttr{name, strconv.Itoa(value)})
+}
+
+// hclString quotes s as an HCL string literal, escaping template sequences
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			// ${ and %{ start template sequences
+			b.WriteRune(r)
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// Nodes and networks are imported by <lab_file>/<name>, <lab_file>/<id> or
+// their resource ID, e.g. /lab.unl/r1, /lab.unl/3 or /lab.unl:node:3.
+
+// splitImportID splits a <lab_file>/<name or id> import ID
+func splitImportID(id, kind string) (labFile, ref string, err error) {
+	i := strings.LastIndex(id, ".unl/")
+	if i < 0 || id[i+len(".unl/"):] == "" {
+		return "", "", fmt.Errorf("invalid import ID %q, expected <lab_file>/<%[2]s name>, <lab_file>/<%[2]s id> or <lab_file>:%[2]s:<id>", id, kind)
+	}
+	return id[:i+len(".unl")], id[i+len(".unl/"):], nil
+}
+
+// resolveImportRef returns the ID of the item named by ref, which is a
+// numeric ID or a name, and must match exactly one item in the lab
+func resolveImportRef(names map[int]string, ref, kind, labFile string) (int, error) {
+	var ids []string
+	if id, err := strconv.Atoi(ref); err == nil {
+		if _, ok := names[id]; ok {
+			ids = append(ids, strconv.Itoa(id))
+		}
+	}
+	for _, id := range sortedKeys(names) {
+		if names[id] == ref && (len(ids) == 0 || ids[0] != strconv.Itoa(id)) {
+			ids = append(ids, strconv.Itoa(id))
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("lab %s has no %s named or numbered %q", labFile, kind, ref)
+	case 1:
+		return strconv.Atoi(ids[0])
+	default:
+		return 0, fmt.Errorf("lab %s has %d %ss matching %q by name or ID (IDs %s); import one of them by ID instead, e.g. %s:%s:%s",
+			labFile, len(ids), kind, ref, strings.Join(ids, ", "), labFile, kind, ids[0])
+	}
+}
+
+// setSchemaDefaults sets the optional attributes an import leaves empty to
+// their defaults, so the first plan after an import is empty
+func setSchemaDefaults(d *schema.ResourceData, s map[string]*schema.Schema) {
+	for k, attr := range s {
+		if attr.Default != nil {
+			_ = d.Set(k, attr.Default)
+		}
+	}
+}
+
+func resourceEveNodeImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*providerMeta).client
+	labFile, nodeID, ok := parseNodeID(d.Id())
+	if !ok {
+		var ref string
+		var err error
+		if labFile, ref, err = splitImportID(d.Id(), "node"); err != nil {
+			return nil, err
+		}
+		nodes, err := listLabNodes(ctx, c, labFile)
+		if err != nil {
+			return nil, err
+		}
+		names := make(map[int]string, len(nodes))
+		for id, node := range nodes {
+			names[id] = node.Name
+		}
+		if nodeID, err = resolveImportRef(names, ref, "node", labFile); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := getNodeData(ctx, c, labFile, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node %d in lab %s: %w", nodeID, labFile, err)
+	}
+
+	setSchemaDefaults(d, nodeSchema())
+	// Keep a running node running
+	if powerStateDrifted(nodeStatusStopped, nodeStates[liveInt(data, "status")]) {
+		_ = d.Set("desired_state", nodeStatusStarted)
+	}
+	_ = d.Set("lab_file", labFile)
+	setNodeID(d, nodeID, labFile)
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceEveNetworkImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*providerMeta).client
+	labFile, netID, ok := parseNetworkID(d.Id())
+	if !ok {
+		var ref string
+		var err error
+		if labFile, ref, err = splitImportID(d.Id(), "network"); err != nil {
+			return nil, err
+		}
+		resp, err := c.GetContext(ctx, "api/labs"+labFile+"/networks")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list networks: %w", err)
+		}
+		networks, err := client.DecodeResponse[indexedList[networkData]](resp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list networks: %w", err)
+		}
+		names := make(map[int]string, len(networks.Data))
+		for id, network := range networks.Data {
+			names[id] = network.Name
+		}
+		if netID, err = resolveImportRef(names, ref, "network", labFile); err != nil {
+			return nil, err
+		}
+	}
+
+	setSchemaDefaults(d, networkSchema())
+	_ = d.Set("lab_file", labFile)
+	d.SetId(labFile + ":network:" + strconv.Itoa(netID))
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceEveLinkImport imports a link by <lab_file>:link:<network_id>; its
+// ends are the two ethernet interfaces attached to the bridge network
+func resourceEveLinkImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*providerMeta).client
+	labFile, networkID, ok := parseLinkID(d.Id())
+	if !ok {
+		return nil, fmt.Errorf("invalid import ID %q, expected <lab_file>:link:<network_id>", d.Id())
+	}
+
+	nodes, err := listLabNodes(ctx, c, labFile)
+	if err != nil {
+		return nil, err
+	}
+	var ends [][2]int
+	for _, nodeID := range sortedKeys(nodes) {
+		ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read interfaces of node %d: %w", nodeID, err)
+		}
+		for _, idx := range sortedKeys(ifaces.Ethernet) {
+			if ifaces.Ethernet[idx].NetworkID == networkID {
+				ends = append(ends, [2]int{nodeID, idx})
+			}
+		}
+	}
+	if len(ends) != 2 {
+		return nil, fmt.Errorf("network %d in lab %s has %d interfaces attached; a link has exactly 2", networkID, labFile, len(ends))
+	}
+
+	_ = d.Set("lab_file", labFile)
+	for i, end := range linkEnds {
+		_ = d.Set(end+"_node_id", ends[i][0])
+		_ = d.Set(end+"_interface_index", ends[i][1])
+	}
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,153 @@
+package eveng
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// nodeConfigEnabled is the node "config" flag telling EVE-NG to boot the
+// node with its stored startup configuration; nodeConfigDisabled boots it
+// with the image defaults
+const (
+	nodeConfigEnabled  = "1"
+	nodeConfigDisabled = "0"
+)
+
+// nodeStartupConfigData is the data returned by GET /api/labs/<lab_file>/configs/<id>
+type nodeStartupConfigData struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+// hashStartupConfig keeps configuration text out of state and plan output
+func hashStartupConfig(config string) string {
+	sum := sha256.Sum256([]byte(config))
+	return hex.EncodeToString(sum[:])
+}
+
+func startupConfigStateFunc(v interface{}) string {
+	return hashStartupConfig(v.(string))
+}
+
+// desiredStartupConfig returns the startup configuration set in raw, which
+// is either inline or read from startup_config_file. managed is false when
+// neither attribute is set; known is false while the value is computed.
+// The raw configuration is used because startup_config holds a hash once
+// it has been stored.
+func desiredStartupConfig(raw cty.Value) (config string, managed, known bool, err error) {
+	if raw.IsNull() || !raw.IsKnown() {
+		return "", false, false, nil
+	}
+
+	inline := raw.GetAttr("startup_config")
+	file := raw.GetAttr("startup_config_file")
+	switch {
+	case !inline.IsNull():
+		if !inline.IsKnown() {
+			return "", true, false, nil
+		}
+		return inline.AsString(), true, true, nil
+	case !file.IsNull():
+		if !file.IsKnown() {
+			return "", true, false, nil
+		}
+		// #nosec G304 -- reading the startup configuration named by the user is the point
+		content, err := os.ReadFile(file.AsString())
+		if err != nil {
+			return "", true, false, fmt.Errorf("failed to read startup_config_file: %w", err)
+		}
+		return string(content), true, true, nil
+	default:
+		return "", false, false, nil
+	}
+}
+
+// startupConfigManaged reports whether the node in state has a startup
+// configuration managed by Terraform
+func startupConfigManaged(d *schema.ResourceData) bool {
+	return d.Get("startup_config").(string) != "" || d.Get("startup_config_file").(string) != ""
+}
+
+// customizeStartupConfigDiff plans an upload when the configured startup
+// configuration differs from the one stored on the server
+func customizeStartupConfigDiff(d *schema.ResourceDiff) error {
+	config, managed, known, err := desiredStartupConfig(d.GetRawConfig())
+	if err != nil {
+		return err
+	}
+	if !managed {
+		return nil
+	}
+	if !known {
+		return d.SetNewComputed("startup_config_hash")
+	}
+	if hash := hashStartupConfig(config); hash != d.Get("startup_config_hash").(string) {
+		log.Printf("[DEBUG] Startup configuration of node %s changed", d.Id())
+		return d.SetNew("startup_config_hash", hash)
+	}
+	return nil
+}
+
+// readStartupConfig returns the startup configuration stored for the node
+func readStartupConfig(ctx context.Context, c *client.Client, labFile string, nodeID int) (string, error) {
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/configs/"+strconv.Itoa(nodeID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get startup config: %w", err)
+	}
+	result, err := client.DecodeResponse[nodeStartupConfigData](resp)
+	if err != nil {
+		return "", fmt.Errorf("failed to read startup config: %w", err)
+	}
+	return result.Data.Data, nil
+}
+
+// writeStartupConfig uploads the node's startup configuration and sets the
+// node's config flag so that it boots with it
+func writeStartupConfig(ctx context.Context, c *client.Client, d *schema.ResourceData, labFile string, nodeID int) error {
+	config, managed, _, err := desiredStartupConfig(d.GetRawConfig())
+	if err != nil || !managed {
+		return err
+	}
+
+	log.Printf("[DEBUG] Uploading startup config of node %d in lab '%s'", nodeID, labFile)
+	resp, err := c.PutContext(ctx, "api/labs"+labFile+"/configs/"+strconv.Itoa(nodeID), map[string]interface{}{"data": config})
+	if err != nil {
+		return fmt.Errorf("failed to upload startup config: %w", err)
+	}
+	if err := c.HandleResponse(resp, nil); err != nil {
+		return fmt.Errorf("failed to upload startup config: %w", err)
+	}
+
+	if err := setNodeConfigFlag(ctx, c, labFile, nodeID, nodeConfigEnabled); err != nil {
+		return fmt.Errorf("failed to enable startup config: %w", err)
+	}
+	return nil
+}
+
+// disableStartupConfig clears the node's config flag once its startup
+// configuration is no longer managed, so that it boots without it
+func disableStartupConfig(ctx context.Context, c *client.Client, labFile string, nodeID int) error {
+	log.Printf("[DEBUG] Disabling startup config of node %d in lab '%s'", nodeID, labFile)
+	if err := setNodeConfigFlag(ctx, c, labFile, nodeID, nodeConfigDisabled); err != nil {
+		return fmt.Errorf("failed to disable startup config: %w", err)
+	}
+	return nil
+}
+
+func setNodeConfigFlag(ctx context.Context, c *client.Client, labFile string, nodeID int, flag string) error {
+	payload := map[string]interface{}{"id": nodeID, "config": flag}
+	resp, err := c.PutContext(ctx, "api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID), payload)
+	if err != nil {
+		return err
+	}
+	return c.HandleResponse(resp, nil)
+}
@@ -0,0 +1,328 @@
+package eveng
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// Modes of the eve_node wait_for block
+const (
+	nodeWaitStatus       = "status"
+	nodeWaitTCPPort      = "tcp_port"
+	nodeWaitConsoleRegex = "console_regex"
+)
+
+const (
+	nodeWaitPending = "waiting"
+	nodeWaitReady   = "ready"
+
+	// nodeStopPollInterval is how often a stopping node is checked
+	nodeStopPollInterval = 2 * time.Second
+	// nodeWaitDialTimeout bounds a single connection attempt to the node
+	nodeWaitDialTimeout = 5 * time.Second
+	// nodeConsoleReadWindow is how long each poll collects console output
+	nodeConsoleReadWindow = 1 * time.Second
+	// nodeConsoleBufferSize caps how much console output is kept for matching
+	nodeConsoleBufferSize = 64 * 1024
+)
+
+func nodeWaitForSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"mode": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      nodeWaitStatus,
+					ValidateFunc: validation.StringInSlice([]string{nodeWaitStatus, nodeWaitTCPPort, nodeWaitConsoleRegex}, false),
+				},
+				// tcp_port: defaults to management_address, then the console host
+				"address": {Type: schema.TypeString, Optional: true, Default: ""},
+				// tcp_port: defaults to the console port
+				"port":          {Type: schema.TypeInt, Optional: true, Default: 0},
+				"console_regex": {Type: schema.TypeString, Optional: true, Default: "", ValidateFunc: validation.StringIsValidRegExp},
+				"timeout":       {Type: schema.TypeString, Optional: true, Default: "10m", ValidateFunc: validateDuration},
+				"poll_interval": {Type: schema.TypeString, Optional: true, Default: "5s", ValidateFunc: validateDuration},
+			},
+		},
+	}
+}
+
+func validateDuration(v interface{}, k string) (warnings []string, errs []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a duration such as \"30s\" or \"5m\": %w", k, err))
+	}
+	return warnings, errs
+}
+
+// nodeWaitCondition is the expanded wait_for block
+type nodeWaitCondition struct {
+	mode         string
+	address      string
+	port         int
+	consoleRegex *regexp.Regexp
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// expandNodeWaitFor returns the configured wait condition, or nil when the
+// node has no wait_for block
+func expandNodeWaitFor(d *schema.ResourceData) (*nodeWaitCondition, error) {
+	blocks := d.Get("wait_for").([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil, nil
+	}
+	block := blocks[0].(map[string]interface{})
+
+	cond := &nodeWaitCondition{
+		mode:    block["mode"].(string),
+		address: block["address"].(string),
+		port:    block["port"].(int),
+	}
+	if cond.address == "" {
+		cond.address = d.Get("management_address").(string)
+	}
+
+	var err error
+	if cond.timeout, err = time.ParseDuration(block["timeout"].(string)); err != nil {
+		return nil, fmt.Errorf("invalid wait_for timeout: %w", err)
+	}
+	if cond.pollInterval, err = time.ParseDuration(block["poll_interval"].(string)); err != nil {
+		return nil, fmt.Errorf("invalid wait_for poll_interval: %w", err)
+	}
+
+	if cond.mode == nodeWaitConsoleRegex {
+		pattern := block["console_regex"].(string)
+		if pattern == "" {
+			return nil, errors.New("wait_for console_regex must be set when mode is \"console_regex\"")
+		}
+		if cond.consoleRegex, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid wait_for console_regex: %w", err)
+		}
+	}
+	return cond, nil
+}
+
+// waitForNode polls the node until the wait condition holds or its timeout
+// expires
+func waitForNode(ctx context.Context, c *client.Client, labFile string, nodeID int, cond *nodeWaitCondition) error {
+	log.Printf("[DEBUG] Waiting up to %s for node %d in lab '%s' (%s)", cond.timeout, nodeID, labFile, cond.mode)
+
+	var refresh retry.StateRefreshFunc
+	switch cond.mode {
+	case nodeWaitTCPPort:
+		refresh = nodeTCPPortRefresh(ctx, c, labFile, nodeID, cond)
+	case nodeWaitConsoleRegex:
+		console := &consoleWatcher{pattern: cond.consoleRegex}
+		defer console.close()
+		refresh = nodeConsoleRefresh(ctx, c, labFile, nodeID, console)
+	default:
+		refresh = nodeStatusRefresh(ctx, c, labFile, nodeID, nodeStatusStarted)
+	}
+
+	conf := &retry.StateChangeConf{
+		Pending:      []string{nodeWaitPending},
+		Target:       []string{nodeWaitReady},
+		Refresh:      refresh,
+		Timeout:      cond.timeout,
+		PollInterval: cond.pollInterval,
+	}
+	if _, err := conf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("node %d did not become ready (%s): %w", nodeID, cond.mode, err)
+	}
+
+	log.Printf("[DEBUG] Node %d is ready", nodeID)
+	return nil
+}
+
+// waitForNodeState polls the node until EVE-NG reports the given power state
+func waitForNodeState(ctx context.Context, c *client.Client, labFile string, nodeID int, state string, timeout time.Duration) error {
+	conf := &retry.StateChangeConf{
+		Pending:      []string{nodeWaitPending},
+		Target:       []string{nodeWaitReady},
+		Refresh:      nodeStatusRefresh(ctx, c, labFile, nodeID, state),
+		Timeout:      timeout,
+		PollInterval: nodeStopPollInterval,
+	}
+	_, err := conf.WaitForStateContext(ctx)
+	return err
+}
+
+// getNodeData reads the raw node object
+func getNodeData(ctx context.Context, c *client.Client, labFile string, nodeID int) (map[string]interface{}, error) {
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID))
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.DecodeResponse[map[string]interface{}](resp)
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// nodeStatusRefresh is ready once EVE-NG reports the node in the given state
+func nodeStatusRefresh(ctx context.Context, c *client.Client, labFile string, nodeID int, state string) retry.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		data, err := getNodeData(ctx, c, labFile, nodeID)
+		if err != nil {
+			return nil, "", err
+		}
+		status, _ := data["status"].(float64)
+		if nodeStates[int(status)] == state {
+			return data, nodeWaitReady, nil
+		}
+		return data, nodeWaitPending, nil
+	}
+}
+
+// nodeConsoleAddress returns the host and port of the node's telnet console
+// from the url EVE-NG reports, e.g. telnet://eve.example.com:32769
+func nodeConsoleAddress(c *client.Client, data map[string]interface{}) (string, int, error) {
+	raw, _ := data["url"].(string)
+	u, err := url.Parse(raw)
+	if err != nil || u.Port() == "" {
+		return "", 0, fmt.Errorf("node has no console url (got %q)", raw)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid console port in %q: %w", raw, err)
+	}
+	host := u.Hostname()
+	if host == "" {
+		host = c.Host()
+	}
+	return host, port, nil
+}
+
+// nodeTCPPortRefresh is ready once a TCP connection to the node succeeds
+func nodeTCPPortRefresh(ctx context.Context, c *client.Client, labFile string, nodeID int, cond *nodeWaitCondition) retry.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		data, err := getNodeData(ctx, c, labFile, nodeID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		host, port := cond.address, cond.port
+		if host == "" || port == 0 {
+			consoleHost, consolePort, err := nodeConsoleAddress(c, data)
+			if err != nil {
+				// The console is only assigned once the node is running
+				log.Printf("[DEBUG] Node %d console not available yet: %v", nodeID, err)
+				return data, nodeWaitPending, nil
+			}
+			if host == "" {
+				host = consoleHost
+			}
+			if port == 0 {
+				port = consolePort
+			}
+		}
+
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		dialer := net.Dialer{Timeout: nodeWaitDialTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			log.Printf("[DEBUG] Node %d not reachable on %s yet: %v", nodeID, addr, err)
+			return data, nodeWaitPending, nil
+		}
+		conn.Close()
+		return data, nodeWaitReady, nil
+	}
+}
+
+// consoleWatcher keeps a console connection open across polls and collects
+// its output
+type consoleWatcher struct {
+	pattern *regexp.Regexp
+	conn    net.Conn
+	output  []byte
+}
+
+func (w *consoleWatcher) close() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// poll reads whatever the console printed since the last poll and reports
+// whether the output matches the pattern
+func (w *consoleWatcher) poll(ctx context.Context, addr string, readFor time.Duration) (bool, error) {
+	if w.conn == nil {
+		dialer := net.Dialer{Timeout: nodeWaitDialTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return false, err
+		}
+		w.conn = conn
+		// Nudge the console so an idle prompt is printed again
+		_, _ = conn.Write([]byte("\r\n"))
+	}
+
+	buf := make([]byte, 4096)
+	deadline := time.Now().Add(readFor)
+	for {
+		if err := w.conn.SetReadDeadline(deadline); err != nil {
+			return false, err
+		}
+		n, err := w.conn.Read(buf)
+		w.output = append(w.output, buf[:n]...)
+		if len(w.output) > nodeConsoleBufferSize {
+			w.output = w.output[len(w.output)-nodeConsoleBufferSize:]
+		}
+		if w.pattern.Match(w.output) {
+			return true, nil
+		}
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return false, nil
+			}
+			// Reconnect on the next poll
+			w.close()
+			return false, err
+		}
+	}
+}
+
+// nodeConsoleRefresh is ready once the node's console output matches the
+// configured regular expression
+func nodeConsoleRefresh(ctx context.Context, c *client.Client, labFile string, nodeID int, console *consoleWatcher) retry.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		data, err := getNodeData(ctx, c, labFile, nodeID)
+		if err != nil {
+			return nil, "", err
+		}
+		host, port, err := nodeConsoleAddress(c, data)
+		if err != nil {
+			log.Printf("[DEBUG] Node %d console not available yet: %v", nodeID, err)
+			return data, nodeWaitPending, nil
+		}
+
+		matched, err := console.poll(ctx, net.JoinHostPort(host, strconv.Itoa(port)), nodeConsoleReadWindow)
+		if err != nil {
+			log.Printf("[DEBUG] Node %d console not readable yet: %v", nodeID, err)
+			return data, nodeWaitPending, nil
+		}
+		if matched {
+			return data, nodeWaitReady, nil
+		}
+		return data, nodeWaitPending, nil
+	}
+}
@@ -48,6 +48,24 @@ func Provider() *schema.Provider {
 				Default:     "30s",
 				Description: "Timeout for API requests",
 			},
+			"retry_max": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     3,
+				Description: "Maximum number of retries for transient API failures",
+			},
+			"retry_wait_min": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "1s",
+				Description: "Minimum wait between retries",
+			},
+			"retry_wait_max": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "30s",
+				Description: "Maximum wait between retries",
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"eve_lab_lock":             resourceEveLabLock(),
@@ -61,6 +79,8 @@ func Provider() *schema.Provider {
 			"eve_network":              resourceEveNetwork(),
 			"eve_node":                 resourceEveNode(),
 			"eve_interface_attachment": resourceEveInterfaceAttachment(),
+			"eve_lab_topology":         resourceEveLabTopology(),
+			"eve_link":                 resourceEveLink(),
 			"eve_user":                 resourceEveUser(),
 			"eve_system_config":        resourceEveSystemConfig(),
 			"eve_lab_export":           resourceEveLabExport(),
@@ -68,9 +88,15 @@ func Provider() *schema.Provider {
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"eve_templates":     dataSourceEveTemplates(),
+			"eve_template":      dataSourceEveTemplate(),
 			"eve_network_types": dataSourceEveNetworkTypes(),
 			"eve_icons":         dataSourceEveIcons(),
 			"eve_status":        dataSourceEveStatus(),
+			"eve_node_configs":  dataSourceEveNodeConfigs(),
+			"eve_labs":          dataSourceEveLabs(),
+			"eve_lab":           dataSourceEveLab(),
+			"eve_nodes":         dataSourceEveNodes(),
+			"eve_networks":      dataSourceEveNetworks(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -83,18 +109,40 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 		return nil, diag.FromErr(fmt.Errorf("invalid timeout format: %w", err))
 	}
 
+	retryWaitMin, err := time.ParseDuration(d.Get("retry_wait_min").(string))
+	if err != nil {
+		return nil, diag.FromErr(fmt.Errorf("invalid retry_wait_min format: %w", err))
+	}
+	retryWaitMax, err := time.ParseDuration(d.Get("retry_wait_max").(string))
+	if err != nil {
+		return nil, diag.FromErr(fmt.Errorf("invalid retry_wait_max format: %w", err))
+	}
+	if retryWaitMax < retryWaitMin {
+		return nil, diag.Errorf("retry_wait_max (%s) must not be less than retry_wait_min (%s)", retryWaitMax, retryWaitMin)
+	}
+
 	config := &client.Config{
 		Endpoint:           d.Get("endpoint").(string),
 		Username:           d.Get("username").(string),
 		Password:           d.Get("password").(string),
 		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
 		Timeout:            timeout,
+		RetryMax:           d.Get("retry_max").(int),
+		RetryWaitMin:       retryWaitMin,
+		RetryWaitMax:       retryWaitMax,
 	}
 
-	client, err := client.NewClient(config)
+	c, err := client.NewClient(config)
 	if err != nil {
 		return nil, diag.FromErr(fmt.Errorf("failed to create EVE-NG client: %w", err))
 	}
 
-	return client, nil
+	return &providerMeta{client: c, templates: newTemplateCatalog()}, nil
+}
+
+// providerMeta is the configured provider passed to resources and data
+// sources: the API client and the template catalog cached alongside it
+type providerMeta struct {
+	client    *client.Client
+	templates *templateCatalog
 }
@@ -13,6 +13,23 @@ const (
 	pathSeparator = "//"
 )
 
+// folderListData is the data returned by GET /api/folders/<path>
+type folderListData struct {
+	Folders []struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+	} `json:"folders"`
+	Labs []folderLab `json:"labs"`
+}
+
+// folderLab is a lab as listed in a folder
+type folderLab struct {
+	File   string `json:"file"`
+	Path   string `json:"path"`
+	Mtime  string `json:"mtime"`
+	Umtime int64  `json:"umtime"`
+}
+
 func resourceEveFolder() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceEveFolderCreate,
@@ -44,7 +61,7 @@ func resourceEveFolder() *schema.Resource {
 }
 
 func resourceEveFolderCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 
 	path := d.Get("path").(string)
 	name := d.Get("name").(string)
@@ -62,7 +79,7 @@ func resourceEveFolderCreate(ctx context.Context, d *schema.ResourceData, m inte
 		"name": name,
 	}
 
-	resp, err := c.Post("api/folders", createData)
+	resp, err := c.PostContext(ctx, "api/folders", createData)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -81,8 +98,8 @@ func resourceEveFolderCreate(ctx context.Context, d *schema.ResourceData, m inte
 	return resourceEveFolderRead(ctx, d, m)
 }
 
-func resourceEveFolderRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveFolderRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
 	fullPath := d.Id()
 
@@ -92,25 +109,14 @@ func resourceEveFolderRead(_ context.Context, d *schema.ResourceData, m interfac
 		apiPath = ""
 	}
 
-	resp, err := c.Get("api/folders" + apiPath)
+	resp, err := c.GetContext(ctx, "api/folders"+apiPath)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Folders []struct {
-				Name string `json:"name"`
-				Path string `json:"path"`
-			} `json:"folders"`
-		} `json:"data"`
-	}
-
-	if err := c.HandleResponse(resp, &result); err != nil {
-		return diag.FromErr(err)
+	result, err := client.DecodeResponse[folderListData](resp)
+	if err != nil {
+		return handleReadError(d, err, "folder "+fullPath)
 	}
 
 	// Find the folder in the response
@@ -167,8 +173,8 @@ func setFolderPathAndName(d *schema.ResourceData, fullPath string) error {
 	return nil
 }
 
-func resourceEveFolderDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveFolderDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
 	fullPath := d.Id()
 
@@ -178,7 +184,7 @@ func resourceEveFolderDelete(_ context.Context, d *schema.ResourceData, m interf
 		return diag.Errorf("cannot delete root folder")
 	}
 
-	resp, err := c.Delete("api/folders" + apiPath)
+	resp, err := c.DeleteContext(ctx, "api/folders"+apiPath)
 	if err != nil {
 		return diag.FromErr(err)
 	}
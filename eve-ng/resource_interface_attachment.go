@@ -2,7 +2,9 @@ package eveng
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 
@@ -17,16 +19,169 @@ func resourceEveInterfaceAttachment() *schema.Resource {
 		ReadContext:   resourceEveIfAttachRead,
 		UpdateContext: resourceEveIfAttachApply,
 		DeleteContext: resourceEveIfAttachDelete,
+		CustomizeDiff: resourceEveIfAttachCustomizeDiff,
 		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
 		Schema: map[string]*schema.Schema{
-			"lab_file":        {Type: schema.TypeString, Required: true, ForceNew: true},
-			"node_id":         {Type: schema.TypeInt, Required: true, ForceNew: true},
-			"interface_index": {Type: schema.TypeInt, Required: true, ForceNew: true},
-			"target":          {Type: schema.TypeString, Required: true}, // network:<id> or node:<remote_node_id>[:<remote_if>]
+			"lab_file": {Type: schema.TypeString, Required: true}, // follows in-place lab renames
+			"node_id":  {Type: schema.TypeInt, Required: true, ForceNew: true},
+			"interface_index": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"interface_index", "interface_name"},
+			},
+			// e.g. Gi0/1 or eth3, resolved against the node's interfaces
+			"interface_name": {Type: schema.TypeString, Optional: true, Computed: true, ForceNew: true},
+			"target": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"target", "remote_node_id"},
+			}, // network:<id> or node:<remote_node_id>[:<remote_if>]
+			// serial link to another node, connected on both nodes
+			"remote_node_id":   {Type: schema.TypeInt, Optional: true, Computed: true, RequiredWith: []string{"remote_interface"}},
+			"remote_interface": {Type: schema.TypeString, Optional: true, Computed: true, RequiredWith: []string{"remote_node_id"}}, // e.g. s1/0
 		},
 	}
 }
 
+// nodeEthernet is an ethernet interface as reported by EVE-NG; a zero
+// network_id means the interface is not connected
+type nodeEthernet struct {
+	Name      string `json:"name"`
+	NetworkID int    `json:"network_id"`
+}
+
+// nodeSerial is a serial interface as reported by EVE-NG; a missing or zero
+// remote_id means the interface is not connected
+type nodeSerial struct {
+	Name     string `json:"name"`
+	RemoteID *int   `json:"remote_id"`
+	RemoteIf *int   `json:"remote_if"`
+}
+
+// indexedList holds items keyed by their numeric index or ID. EVE-NG returns
+// some collections as a JSON array and others as an object keyed by index
+// (interfaces of IOL nodes, or any empty collection), so both forms are
+// accepted.
+type indexedList[T any] map[int]T
+
+func (l *indexedList[T]) UnmarshalJSON(b []byte) error {
+	var list []T
+	if err := json.Unmarshal(b, &list); err == nil {
+		*l = make(indexedList[T], len(list))
+		for i, v := range list {
+			(*l)[i] = v
+		}
+		return nil
+	}
+
+	var keyed map[string]T
+	if err := json.Unmarshal(b, &keyed); err != nil {
+		return err
+	}
+	*l = make(indexedList[T], len(keyed))
+	for k, v := range keyed {
+		idx, err := strconv.Atoi(k)
+		if err != nil {
+			return fmt.Errorf("invalid index %q: %w", k, err)
+		}
+		(*l)[idx] = v
+	}
+	return nil
+}
+
+// nodeInterfacesData is the data returned by GET /api/labs/<lab_file>/nodes/<id>/interfaces
+type nodeInterfacesData struct {
+	Ethernet indexedList[nodeEthernet] `json:"ethernet"`
+	Serial   indexedList[nodeSerial]   `json:"serial"`
+	ID       int                       `json:"id"`
+	Sort     string                    `json:"sort"`
+}
+
+// attachedTarget returns the target an interface is connected to, in the
+// same form as the target attribute, or false when it is detached or does
+// not exist. configured is the target currently in state; a serial link
+// configured without a remote interface keeps that shorter form.
+func (n *nodeInterfacesData) attachedTarget(ifIndex int, configured string) (string, bool) {
+	if eth, ok := n.Ethernet[ifIndex]; ok {
+		if eth.NetworkID == 0 {
+			return "", false
+		}
+		return "network:" + strconv.Itoa(eth.NetworkID), true
+	}
+
+	serial, ok := n.Serial[ifIndex]
+	if !ok || serial.RemoteID == nil || *serial.RemoteID == 0 {
+		return "", false
+	}
+	target := "node:" + strconv.Itoa(*serial.RemoteID)
+	if serial.RemoteIf != nil && configured != target {
+		target += ":" + strconv.Itoa(*serial.RemoteIf)
+	}
+	return target, true
+}
+
+// interfaceName returns the name of the ethernet or serial interface at the
+// given index
+func (n *nodeInterfacesData) interfaceName(ifIndex int) (string, bool) {
+	if eth, ok := n.Ethernet[ifIndex]; ok {
+		return eth.Name, true
+	}
+	if serial, ok := n.Serial[ifIndex]; ok {
+		return serial.Name, true
+	}
+	return "", false
+}
+
+// interfaceIndex resolves an interface name to its index. serial reports
+// whether it is a serial interface. The error lists the valid names.
+func (n *nodeInterfacesData) interfaceIndex(name string) (ifIndex int, serial bool, err error) {
+	for idx, eth := range n.Ethernet {
+		if eth.Name == name {
+			return idx, false, nil
+		}
+	}
+	for idx, s := range n.Serial {
+		if s.Name == name {
+			return idx, true, nil
+		}
+	}
+
+	var names []string
+	for _, idx := range sortedKeys(n.Ethernet) {
+		names = append(names, n.Ethernet[idx].Name)
+	}
+	for _, idx := range sortedKeys(n.Serial) {
+		names = append(names, n.Serial[idx].Name)
+	}
+	return 0, false, fmt.Errorf("node %d has no interface %q (valid names: %s)", n.ID, name, strings.Join(names, ", "))
+}
+
+// getNodeInterfaces reads the interfaces of a node and what they connect to
+func getNodeInterfaces(ctx context.Context, c *client.Client, labFile string, nodeID int) (*nodeInterfacesData, error) {
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID)+"/interfaces")
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.DecodeResponse[nodeInterfacesData](resp)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Data, nil
+}
+
+// putNodeInterfaces connects node interfaces, given as index to network ID
+// (0 to disconnect) or to a serial remote
+func putNodeInterfaces(ctx context.Context, c *client.Client, labFile string, nodeID int, payload map[string]interface{}) error {
+	resp, err := c.PutContext(ctx, "api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID)+"/interfaces", payload)
+	if err != nil {
+		return err
+	}
+	return c.HandleResponse(resp, nil)
+}
+
 func makeIfAttachID(labFile string, nodeID, ifIndex int) string {
 	return fmt.Sprintf("%s:ifattach:%d:%d", labFile, nodeID, ifIndex)
 }
@@ -53,93 +208,220 @@ func parseIfAttachID(id string) (labFile string, nodeID, ifIndex int, ok bool) {
 	return lab, nid, idx, true
 }
 
+// resolveIfAttachIndex returns the index of the named interface of the node
+func resolveIfAttachIndex(ctx context.Context, c *client.Client, labFile string, nodeID int, name string) (int, error) {
+	ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read interfaces of node %d: %w", nodeID, err)
+	}
+	ifIndex, _, err := ifaces.interfaceIndex(name)
+	return ifIndex, err
+}
+
+func resourceEveIfAttachCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if _, nodeID, ifIndex, ok := parseIfAttachID(d.Id()); ok {
+		if err := customizeLabFileDiff(ctx, d, m.(*providerMeta).client, sameIfAttach(d, nodeID, ifIndex)); err != nil {
+			return err
+		}
+	}
+	raw := d.GetRawConfig()
+	if raw.IsNull() {
+		return nil
+	}
+	if err := customizeIfAttachSerialDiff(d, raw.GetAttr("remote_node_id").IsNull()); err != nil {
+		return err
+	}
+	if raw.GetAttr("interface_name").IsNull() {
+		return nil
+	}
+	return customizeIfAttachNameDiff(ctx, d, m.(*providerMeta).client)
+}
+
+// customizeIfAttachNameDiff resolves interface_name at plan time when the
+// node already exists, so that unknown names fail before apply
+func customizeIfAttachNameDiff(ctx context.Context, d *schema.ResourceDiff, c *client.Client) error {
+	if !d.HasChange("interface_name") {
+		return nil
+	}
+	if !d.NewValueKnown("interface_name") || !d.NewValueKnown("lab_file") || !d.NewValueKnown("node_id") {
+		return d.SetNewComputed("interface_index")
+	}
+	name := d.Get("interface_name").(string)
+	if name == "" {
+		return nil
+	}
+
+	ifIndex, err := resolveIfAttachIndex(ctx, c, d.Get("lab_file").(string), d.Get("node_id").(int), name)
+	if err != nil {
+		return err
+	}
+	return d.SetNew("interface_index", ifIndex)
+}
+
+// customizeIfAttachSerialDiff marks target and the remote attributes, which
+// describe the same serial link, as changing together
+func customizeIfAttachSerialDiff(d *schema.ResourceDiff, byTarget bool) error {
+	if !byTarget {
+		if d.HasChange("remote_node_id") || d.HasChange("remote_interface") {
+			return d.SetNewComputed("target")
+		}
+		return nil
+	}
+	if d.HasChange("target") && strings.HasPrefix(d.Get("target").(string), "node:") {
+		if err := d.SetNewComputed("remote_node_id"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("remote_interface")
+	}
+	return nil
+}
+
+// sameIfAttach matches the attached interface in state by name
+func sameIfAttach(d resourceChange, nodeID, ifIndex int) sameLabObject {
+	return func(ctx context.Context, c *client.Client, labFile string) (bool, error) {
+		ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
+		if err != nil {
+			return false, err
+		}
+		oldName, _ := d.GetChange("interface_name")
+		name, ok := ifaces.interfaceName(ifIndex)
+		return ok && (oldName == "" || name == oldName), nil
+	}
+}
+
 func resourceEveIfAttachApply(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 	nodeID := d.Get("node_id").(int)
 	ifIndex := d.Get("interface_index").(int)
 	target := d.Get("target").(string)
 
-	payload := map[string]interface{}{}
-	// Decide value form based on target
-	if strings.HasPrefix(target, "network:") {
-		idStr := strings.TrimPrefix(target, "network:")
-		nid, _ := strconv.Atoi(idStr)
-		payload[strconv.Itoa(ifIndex)] = nid
-	} else if strings.HasPrefix(target, "node:") {
-		// pass-through remote mapping string (e.g., "<remote_id>" or "<remote_id>:<remote_if>")
-		payload[strconv.Itoa(ifIndex)] = strings.TrimPrefix(target, "node:")
-	} else {
-		return diag.Errorf("invalid target format: %s", target)
+	// The lab was renamed or relocated: the node must have moved with it
+	if d.HasChange("lab_file") && !d.IsNewResource() {
+		if err := checkRenamedLabObject(ctx, c, fmt.Sprintf("interface %d of node %d", ifIndex, nodeID), labFile, sameIfAttach(d, nodeID, ifIndex)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// The node may not have existed at plan time
+	if name := d.Get("interface_name").(string); name != "" && d.IsNewResource() {
+		var err error
+		if ifIndex, err = resolveIfAttachIndex(ctx, c, labFile, nodeID, name); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
-	resp, err := c.Put("api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID)+"/interfaces", payload)
+	peer, serial, err := configuredSerialPeer(ctx, c, d, labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if err := c.HandleResponse(resp, nil); err != nil {
-		return diag.FromErr(err)
+	if !serial && strings.HasPrefix(target, "node:") {
+		if peer, err = parseSerialTarget(target); err != nil {
+			return diag.FromErr(err)
+		}
+		serial = true
+	}
+
+	switch {
+	case serial:
+		// Release the previous peer when the link moves
+		if oldTarget, _ := d.GetChange("target"); !d.IsNewResource() {
+			if oldPeer, err := parseSerialTarget(oldTarget.(string)); err == nil && oldPeer != peer {
+				if err := disconnectSerialPeer(ctx, c, labFile, nodeID, ifIndex, oldPeer); err != nil {
+					return diag.FromErr(fmt.Errorf("failed to disconnect previous serial peer: %w", err))
+				}
+			}
+		}
+		if err := connectSerial(ctx, c, labFile, nodeID, ifIndex, peer); err != nil {
+			return diag.FromErr(err)
+		}
+	case strings.HasPrefix(target, "network:"):
+		nid, _ := strconv.Atoi(strings.TrimPrefix(target, "network:"))
+		if err := putNodeInterfaces(ctx, c, labFile, nodeID, map[string]interface{}{strconv.Itoa(ifIndex): nid}); err != nil {
+			return diag.FromErr(err)
+		}
+	default:
+		return diag.Errorf("invalid target format: %s", target)
 	}
 
 	d.SetId(makeIfAttachID(labFile, nodeID, ifIndex))
 	return resourceEveIfAttachRead(ctx, d, m)
 }
 
-func resourceEveIfAttachRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
-	labFile, nodeID, _, ok := parseIfAttachID(d.Id())
+func resourceEveIfAttachRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile, nodeID, ifIndex, ok := parseIfAttachID(d.Id())
 	if !ok {
 		d.SetId("")
 		return nil
 	}
 
-	resp, err := c.Get("api/labs" + labFile + "/nodes/" + strconv.Itoa(nodeID) + "/interfaces")
+	ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
 	if err != nil {
-		return diag.FromErr(err)
+		return handleReadError(d, err, fmt.Sprintf("interfaces of node %d in lab %s", nodeID, labFile))
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Ethernet []struct {
-				Name      string `json:"name"`
-				NetworkID int    `json:"network_id"`
-			} `json:"ethernet"`
-			Serial []struct {
-				Name     string `json:"name"`
-				RemoteID *int   `json:"remote_id"`
-				RemoteIf *int   `json:"remote_if"`
-			} `json:"serial"`
-			ID   int    `json:"id"`
-			Sort string `json:"sort"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
+	target, attached := ifaces.attachedTarget(ifIndex, d.Get("target").(string))
+	if !attached {
+		log.Printf("[WARN] Interface %d of node %d in lab %s is detached, removing from state", ifIndex, nodeID, labFile)
 		d.SetId("")
 		return nil
 	}
 
-	// Best-effort: we don't strictly verify mapping equality here
-	_ = result
+	_ = d.Set("lab_file", labFile)
+	_ = d.Set("node_id", nodeID)
+	_ = d.Set("interface_index", ifIndex)
+	if name, ok := ifaces.interfaceName(ifIndex); ok {
+		_ = d.Set("interface_name", name)
+	}
+	_ = d.Set("target", target)
+
+	remoteID, remoteIf, err := serialRemote(ctx, c, labFile, ifaces, ifIndex)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	_ = d.Set("remote_node_id", remoteID)
+	_ = d.Set("remote_interface", remoteIf)
 	return nil
 }
 
-func resourceEveIfAttachDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+// serialRemote returns the remote node and interface name a serial interface
+// is linked to, or zero values for ethernet interfaces
+func serialRemote(ctx context.Context, c *client.Client, labFile string, ifaces *nodeInterfacesData, ifIndex int) (int, string, error) {
+	serial, ok := ifaces.Serial[ifIndex]
+	if !ok || serial.RemoteID == nil {
+		return 0, "", nil
+	}
+	if serial.RemoteIf == nil {
+		return *serial.RemoteID, "", nil
+	}
+	remote, err := getNodeInterfaces(ctx, c, labFile, *serial.RemoteID)
+	if err != nil {
+		if client.IsNotFound(err) {
+			return *serial.RemoteID, "", nil
+		}
+		return 0, "", fmt.Errorf("failed to read interfaces of node %d: %w", *serial.RemoteID, err)
+	}
+	name, _ := remote.interfaceName(*serial.RemoteIf)
+	return *serial.RemoteID, name, nil
+}
+
+func resourceEveIfAttachDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile, nodeID, ifIndex, ok := parseIfAttachID(d.Id())
 	if !ok {
 		return diag.Errorf("invalid ID format")
 	}
 
 	payload := map[string]interface{}{strconv.Itoa(ifIndex): 0}
-	resp, err := c.Put("api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID)+"/interfaces", payload)
-	if err != nil {
+	if err := putNodeInterfaces(ctx, c, labFile, nodeID, payload); ignoreNotFound(err) != nil {
 		return diag.FromErr(err)
 	}
-	if err := c.HandleResponse(resp, nil); err != nil {
-		return diag.FromErr(err)
+
+	if peer, err := parseSerialTarget(d.Get("target").(string)); err == nil {
+		if err := disconnectSerialPeer(ctx, c, labFile, nodeID, ifIndex, peer); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to disconnect serial peer: %w", err))
+		}
 	}
 	return nil
 }
@@ -2,9 +2,11 @@ package eveng
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -15,19 +17,27 @@ func resourceEveLab() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceEveLabCreate,
 		ReadContext:   resourceEveLabRead,
+		UpdateContext: resourceEveLabUpdate,
 		DeleteContext: resourceEveLabDelete,
+		CustomizeDiff: resourceEveLabCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
-			"path":          {Type: schema.TypeString, Required: true, ForceNew: true},
-			"name":          {Type: schema.TypeString, Required: true, ForceNew: true},
-			"author":        {Type: schema.TypeString, Optional: true, Default: "", ForceNew: true},
-			"description":   {Type: schema.TypeString, Optional: true, Default: "", ForceNew: true},
-			"body":          {Type: schema.TypeString, Optional: true, Default: "", ForceNew: true},
-			"version":       {Type: schema.TypeString, Optional: true, Default: "1", ForceNew: true},
-			"scripttimeout": {Type: schema.TypeInt, Optional: true, Default: 300, ForceNew: true},
-			"lock":          {Type: schema.TypeBool, Optional: true, Default: false, ForceNew: true},
+			"path":          {Type: schema.TypeString, Required: true},
+			"name":          {Type: schema.TypeString, Required: true},
+			"author":        {Type: schema.TypeString, Optional: true, Default: ""},
+			"description":   {Type: schema.TypeString, Optional: true, Default: ""},
+			"body":          {Type: schema.TypeString, Optional: true, Default: ""},
+			"version":       {Type: schema.TypeString, Optional: true, Default: "1"},
+			"scripttimeout": {Type: schema.TypeInt, Optional: true, Default: 300},
+			"lock":          {Type: schema.TypeBool, Optional: true, Default: false},
 			"file":          {Type: schema.TypeString, Computed: true},
 		},
 	}
@@ -40,8 +50,109 @@ func normalizePath(p string) string {
 	return p
 }
 
+// labFilePath returns the lab file EVE-NG stores a lab named name under path
+func labFilePath(path, name string) string {
+	labFile := path
+	if labFile != "/" && !strings.HasSuffix(labFile, "/") {
+		labFile += "/"
+	}
+	return labFile + name + ".unl"
+}
+
+// labFileName returns the name of the lab stored in labFile
+func labFileName(labFile string) string {
+	return strings.TrimSuffix(labFile[strings.LastIndex(labFile, "/")+1:], ".unl")
+}
+
+// resourceEveLabCustomizeDiff leaves the lab file unknown when the lab is
+// renamed or relocated, so that the resources referencing it plan to follow
+// the lab instead of being replaced
+func resourceEveLabCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChanges("path", "name") {
+		return nil
+	}
+	return d.SetNewComputed("file")
+}
+
+// labExists reports whether a lab file exists on the server
+func labExists(ctx context.Context, c *client.Client, labFile string) (bool, error) {
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
+	if err == nil {
+		_, err = client.DecodeResponse[json.RawMessage](resp)
+	}
+	if client.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get lab %s: %w", labFile, err)
+	}
+	return true, nil
+}
+
+// sameLabObject reports whether the object a resource finds under its ID in
+// the given lab is the one in state
+type sameLabObject func(ctx context.Context, c *client.Client, labFile string) (bool, error)
+
+// resourceChange is the state access shared by ResourceData and ResourceDiff
+type resourceChange interface {
+	GetChange(key string) (interface{}, interface{})
+}
+
+// customizeLabFileDiff replaces a resource whose lab_file changes, unless
+// the change follows an in-place rename or relocation of the lab by eve_lab.
+// When planned, such a lab_file is unknown until eve_lab has moved the lab.
+// When planned again during the apply, it is known: the old lab is gone and
+// the object under the same ID in the new lab must be the one in state. A
+// lab that was renamed outside Terraform is followed the same way.
+func customizeLabFileDiff(ctx context.Context, d *schema.ResourceDiff, c *client.Client, same sameLabObject) error {
+	if d.Id() == "" || !d.HasChange("lab_file") {
+		return nil
+	}
+	if !d.NewValueKnown("lab_file") {
+		log.Printf("[DEBUG] Lab of %s is renamed or relocated, keeping it", d.Id())
+		return nil
+	}
+
+	oldFile, newFile := d.GetChange("lab_file")
+	oldExists, err := labExists(ctx, c, oldFile.(string))
+	if err != nil {
+		return err
+	}
+	renamed := false
+	if !oldExists {
+		if renamed, err = same(ctx, c, newFile.(string)); ignoreNotFound(err) != nil {
+			return err
+		}
+	}
+	if !renamed {
+		return d.ForceNew("lab_file")
+	}
+	log.Printf("[DEBUG] Lab %s is renamed to %s, keeping %s", oldFile, newFile, d.Id())
+	return nil
+}
+
+// checkRenamedLabObject verifies that the object found under the same ID in
+// the renamed lab is the one in state, before the resource takes it over
+func checkRenamedLabObject(ctx context.Context, c *client.Client, object, labFile string, same sameLabObject) error {
+	ok, err := same(ctx, c, labFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s in lab %s: %w", object, labFile, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s in lab %s is not the one in state; it was not moved with the lab", object, labFile)
+	}
+	return nil
+}
+
+// sameNamedObject matches the name and type in state against the live ones
+func sameNamedObject(d resourceChange, name, typ string) bool {
+	oldName, _ := d.GetChange("name")
+	oldType, _ := d.GetChange("type")
+	return name == oldName.(string) && typ == oldType.(string)
+}
+
 func resourceEveLabCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 
 	path := normalizePath(d.Get("path").(string))
 	name := d.Get("name").(string)
@@ -70,32 +181,18 @@ func resourceEveLabCreate(ctx context.Context, d *schema.ResourceData, m interfa
 
 	log.Printf("[DEBUG] Lab payload: %+v", payload)
 
-	resp, err := c.Post("api/labs", payload)
+	resp, err := c.PostContext(ctx, "api/labs", payload)
 	if err != nil {
 		log.Printf("[ERROR] Failed to create lab: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to create lab: %w", err))
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
+	if _, err := client.DecodeResponse[json.RawMessage](resp); err != nil {
 		log.Printf("[ERROR] Failed to handle lab creation response: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to handle lab creation response: %w", err))
 	}
 
-	if result.Code != 200 {
-		log.Printf("[ERROR] Lab creation failed with code %d: %s", result.Code, result.Message)
-		return diag.Errorf("lab creation failed: %s", result.Message)
-	}
-
-	labFile := path
-	if labFile != "/" && !strings.HasSuffix(labFile, "/") {
-		labFile += "/"
-	}
-	labFile += name + ".unl"
+	labFile := labFilePath(path, name)
 	d.SetId(labFile)
 	if err := d.Set("file", labFile); err != nil {
 		return diag.FromErr(err)
@@ -103,49 +200,30 @@ func resourceEveLabCreate(ctx context.Context, d *schema.ResourceData, m interfa
 
 	log.Printf("[DEBUG] Lab created with file: %s", labFile)
 
+	if d.Get("lock").(bool) {
+		if err := setLabLock(ctx, c, labFile, true); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return resourceEveLabRead(ctx, d, m)
 }
 
-func resourceEveLabRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
 	labFile := d.Id()
 	log.Printf("[DEBUG] Reading lab: %s", labFile)
 
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		log.Printf("[ERROR] Failed to get lab: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to get lab: %w", err))
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Author        string      `json:"author"`
-			Description   string      `json:"description"`
-			Body          string      `json:"body"`
-			Filename      string      `json:"filename"`
-			ID            string      `json:"id"`
-			Name          string      `json:"name"`
-			Version       interface{} `json:"version"` // Can be string or int
-			ScriptTimeout int         `json:"scripttimeout"`
-			Lock          interface{} `json:"lock"` // Can be bool or int
-		} `json:"data"`
-	}
-
-	if err := c.HandleResponse(resp, &result); err != nil {
-		log.Printf("[ERROR] Failed to handle lab read response: %v", err)
-		// NotFound -> clear state
-		d.SetId("")
-		return nil
-	}
-
-	if result.Code != 200 {
-		log.Printf("[ERROR] Lab read failed with code %d: %s", result.Code, result.Message)
-		d.SetId("")
-		return nil
+	result, err := client.DecodeResponse[labData](resp)
+	if err != nil {
+		return handleReadError(d, err, "lab "+labFile)
 	}
 
 	// Handle version and lock fields
@@ -187,7 +265,8 @@ func handleLockField(lock interface{}) bool {
 	}
 }
 
-func setLabDataFromResponse(d *schema.ResourceData, data *struct {
+// labData is the lab object returned by GET /api/labs/<lab_file>
+type labData struct {
 	Author        string      `json:"author"`
 	Description   string      `json:"description"`
 	Body          string      `json:"body"`
@@ -197,7 +276,19 @@ func setLabDataFromResponse(d *schema.ResourceData, data *struct {
 	Version       interface{} `json:"version"` // Can be string or int
 	ScriptTimeout int         `json:"scripttimeout"`
 	Lock          interface{} `json:"lock"` // Can be bool or int
-}, labFile, version string, lock bool) error {
+}
+
+func setLabDataFromResponse(d *schema.ResourceData, data *labData, labFile, version string, lock bool) error {
+	// Keep path and name as written unless they no longer point at the lab
+	// file, after an import or a move outside Terraform
+	if labFilePath(normalizePath(d.Get("path").(string)), d.Get("name").(string)) != labFile {
+		if err := d.Set("path", labFolder(labFile)); err != nil {
+			return err
+		}
+		if err := d.Set("name", labFileName(labFile)); err != nil {
+			return err
+		}
+	}
 	if err := d.Set("author", data.Author); err != nil {
 		return err
 	}
@@ -222,33 +313,109 @@ func setLabDataFromResponse(d *schema.ResourceData, data *struct {
 	return nil
 }
 
-func resourceEveLabDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c := m.(*providerMeta).client
+
+	labFile := d.Id()
+	log.Printf("[DEBUG] Updating lab: %s", labFile)
+
+	// A locked lab rejects edits: unlock before changing it and lock after.
+	// A lab that stays locked is locked again even when the update fails.
+	oldLock, _ := d.GetChange("lock")
+	wasLocked, lock := oldLock.(bool), d.Get("lock").(bool)
+	edited := d.HasChangesExcept("lock")
+	if wasLocked && (!lock || edited) {
+		if err := setLabLock(ctx, c, labFile, false); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	relock := lock && (!wasLocked || edited)
+	defer func() {
+		if relock && wasLocked {
+			if err := setLabLock(ctx, c, labFile, true); err != nil {
+				diags = append(diags, diag.FromErr(err)...)
+			}
+		}
+	}()
+
+	// Rename and relocate through the move API so nodes and their disks survive
+	if d.HasChanges("path", "name") {
+		path := normalizePath(d.Get("path").(string))
+		name := d.Get("name").(string)
+
+		log.Printf("[DEBUG] Moving lab '%s' to '%s'", labFile, labFilePath(path, name))
+
+		newLabFile, err := moveLab(ctx, c, labFile, path, name)
+		if err != nil {
+			log.Printf("[ERROR] Failed to move lab: %v", err)
+			return diag.FromErr(fmt.Errorf("failed to move lab: %w", err))
+		}
+		labFile = newLabFile
+		d.SetId(labFile)
+		if err := d.Set("file", labFile); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	payload := map[string]interface{}{}
+	if d.HasChange("author") {
+		payload["author"] = d.Get("author").(string)
+	}
+	if d.HasChange("description") {
+		payload["description"] = d.Get("description").(string)
+	}
+	if d.HasChange("body") {
+		payload["body"] = d.Get("body").(string)
+	}
+	if d.HasChange("version") {
+		payload["version"] = d.Get("version").(string)
+	}
+	if d.HasChange("scripttimeout") {
+		payload["scripttimeout"] = d.Get("scripttimeout").(int)
+	}
+
+	if len(payload) > 0 {
+		log.Printf("[DEBUG] Lab update payload: %+v", payload)
+
+		resp, err := c.PutContext(ctx, "api/labs"+labFile, payload)
+		if err != nil {
+			log.Printf("[ERROR] Failed to update lab: %v", err)
+			return diag.FromErr(fmt.Errorf("failed to update lab: %w", err))
+		}
+		if err := c.HandleResponse(resp, nil); err != nil {
+			log.Printf("[ERROR] Failed to handle lab update response: %v", err)
+			return diag.FromErr(fmt.Errorf("failed to handle lab update response: %w", err))
+		}
+	}
+
+	if relock {
+		relock = false
+		if err := setLabLock(ctx, c, labFile, true); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	log.Printf("[DEBUG] Lab updated successfully")
+	return resourceEveLabRead(ctx, d, m)
+}
+
+func resourceEveLabDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
 	labFile := d.Id()
 	log.Printf("[DEBUG] Deleting lab: %s", labFile)
 
-	resp, err := c.Delete("api/labs" + labFile)
+	resp, err := c.DeleteContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		log.Printf("[ERROR] Failed to delete lab: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to delete lab: %w", err))
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
+	if _, err := client.DecodeResponse[json.RawMessage](resp); ignoreNotFound(err) != nil {
 		log.Printf("[ERROR] Failed to handle lab delete response: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to handle lab delete response: %w", err))
 	}
 
-	if result.Code != 200 {
-		log.Printf("[ERROR] Lab deletion failed with code %d: %s", result.Code, result.Message)
-		return diag.Errorf("lab deletion failed: %s", result.Message)
-	}
-
 	log.Printf("[DEBUG] Lab deleted successfully")
 	return nil
 }
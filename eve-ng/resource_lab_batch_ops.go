@@ -2,10 +2,10 @@ package eveng
 
 import (
 	"context"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
 )
 
 // batchOperationType represents the type of batch operation
@@ -17,9 +17,18 @@ const (
 	batchWipe  batchOperationType = "wipe"
 )
 
+// batchOperationTimeouts returns the timeouts shared by the batch resources
+func batchOperationTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(10 * time.Minute),
+		Read:   schema.DefaultTimeout(5 * time.Minute),
+		Delete: schema.DefaultTimeout(5 * time.Minute),
+	}
+}
+
 // createBatchOperation performs a batch operation on lab nodes
 func createBatchOperation(ctx context.Context, d *schema.ResourceData, m interface{}, opType batchOperationType, readFunc func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 
 	payload := map[string]interface{}{}
@@ -28,7 +37,7 @@ func createBatchOperation(ctx context.Context, d *schema.ResourceData, m interfa
 	}
 
 	endpoint := "api/labs" + labFile + "/nodes/" + string(opType)
-	resp, err := c.Post(endpoint, payload)
+	resp, err := c.PostContext(ctx, endpoint, payload)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -46,6 +55,7 @@ func resourceEveLabBatchStart() *schema.Resource {
 		ReadContext:   resourceEveLabBatchStartRead,
 		DeleteContext: resourceEveLabBatchStartDelete,
 		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
+		Timeouts:      batchOperationTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"lab_file": {Type: schema.TypeString, Required: true, ForceNew: true},
 			"node_ids": {Type: schema.TypeList, Optional: true, ForceNew: true, Elem: &schema.Schema{Type: schema.TypeInt}},
@@ -57,18 +67,17 @@ func resourceEveLabBatchStartCreate(ctx context.Context, d *schema.ResourceData,
 	return createBatchOperation(ctx, d, m, batchStart, resourceEveLabBatchStartRead)
 }
 
-func resourceEveLabBatchStartRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceEveLabBatchStartRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Batch operations are stateless, just verify lab exists
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	if err := c.HandleResponse(resp, nil); err != nil {
-		d.SetId("")
-		return nil
+		return handleReadError(d, err, "lab "+labFile)
 	}
 
 	return nil
@@ -85,6 +94,7 @@ func resourceEveLabBatchStop() *schema.Resource {
 		ReadContext:   resourceEveLabBatchStopRead,
 		DeleteContext: resourceEveLabBatchStopDelete,
 		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
+		Timeouts:      batchOperationTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"lab_file": {Type: schema.TypeString, Required: true, ForceNew: true},
 			"node_ids": {Type: schema.TypeList, Optional: true, ForceNew: true, Elem: &schema.Schema{Type: schema.TypeInt}},
@@ -96,17 +106,16 @@ func resourceEveLabBatchStopCreate(ctx context.Context, d *schema.ResourceData,
 	return createBatchOperation(ctx, d, m, batchStop, resourceEveLabBatchStopRead)
 }
 
-func resourceEveLabBatchStopRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabBatchStopRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	if err := c.HandleResponse(resp, nil); err != nil {
-		d.SetId("")
-		return nil
+		return handleReadError(d, err, "lab "+labFile)
 	}
 
 	return nil
@@ -122,6 +131,7 @@ func resourceEveLabBatchWipe() *schema.Resource {
 		ReadContext:   resourceEveLabBatchWipeRead,
 		DeleteContext: resourceEveLabBatchWipeDelete,
 		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
+		Timeouts:      batchOperationTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"lab_file": {Type: schema.TypeString, Required: true, ForceNew: true},
 			"node_ids": {Type: schema.TypeList, Optional: true, ForceNew: true, Elem: &schema.Schema{Type: schema.TypeInt}},
@@ -133,17 +143,16 @@ func resourceEveLabBatchWipeCreate(ctx context.Context, d *schema.ResourceData,
 	return createBatchOperation(ctx, d, m, batchWipe, resourceEveLabBatchWipeRead)
 }
 
-func resourceEveLabBatchWipeRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabBatchWipeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	if err := c.HandleResponse(resp, nil); err != nil {
-		d.SetId("")
-		return nil
+		return handleReadError(d, err, "lab "+labFile)
 	}
 
 	return nil
@@ -25,7 +25,7 @@ func resourceEveLabClone() *schema.Resource {
 }
 
 func resourceEveLabCloneCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	sourceLabFile := d.Get("source_lab_file").(string)
 	destPath := d.Get("destination_path").(string)
 	newName := d.Get("new_name").(string)
@@ -43,7 +43,7 @@ func resourceEveLabCloneCreate(ctx context.Context, d *schema.ResourceData, m in
 		"name": newName,
 	}
 
-	resp, err := c.Post("api/labs"+sourceLabFile+"/clone", payload)
+	resp, err := c.PostContext(ctx, "api/labs"+sourceLabFile+"/clone", payload)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -59,26 +59,17 @@ func resourceEveLabCloneCreate(ctx context.Context, d *schema.ResourceData, m in
 	return resourceEveLabCloneRead(ctx, d, m)
 }
 
-func resourceEveLabCloneRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabCloneRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	clonedLabFile := strings.TrimSuffix(d.Id(), ":clone")
 
-	resp, err := c.Get("api/labs" + clonedLabFile)
+	resp, err := c.GetContext(ctx, "api/labs"+clonedLabFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Filename string `json:"filename"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
-		d.SetId("")
-		return nil
+	if _, err := client.DecodeResponse[labData](resp); err != nil {
+		return handleReadError(d, err, "cloned lab "+clonedLabFile)
 	}
 
 	if err := d.Set("cloned_lab_file", clonedLabFile); err != nil {
@@ -87,16 +78,16 @@ func resourceEveLabCloneRead(_ context.Context, d *schema.ResourceData, m interf
 	return nil
 }
 
-func resourceEveLabCloneDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabCloneDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	clonedLabFile := strings.TrimSuffix(d.Id(), ":clone")
 
 	// Delete the cloned lab
-	resp, err := c.Delete("api/labs" + clonedLabFile)
+	resp, err := c.DeleteContext(ctx, "api/labs"+clonedLabFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if err := c.HandleResponse(resp, nil); err != nil {
+	if err := c.HandleResponse(resp, nil); ignoreNotFound(err) != nil {
 		return diag.FromErr(err)
 	}
 	return nil
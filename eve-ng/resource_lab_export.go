@@ -26,7 +26,7 @@ func resourceEveLabExport() *schema.Resource {
 }
 
 func resourceEveLabExportCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 	exportFormat := d.Get("export_format").(string)
 	includeConfigs := d.Get("include_configs").(bool)
@@ -36,21 +36,16 @@ func resourceEveLabExportCreate(ctx context.Context, d *schema.ResourceData, m i
 		"configs": includeConfigs,
 	}
 
-	resp, err := c.Post("api/labs"+labFile+"/export", payload)
+	resp, err := c.PostContext(ctx, "api/labs"+labFile+"/export", payload)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			ExportData string `json:"export_data"`
-			Filename   string `json:"filename"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
+	result, err := client.DecodeResponse[struct {
+		ExportData string `json:"export_data"`
+		Filename   string `json:"filename"`
+	}](resp)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -65,18 +60,17 @@ func resourceEveLabExportCreate(ctx context.Context, d *schema.ResourceData, m i
 	return resourceEveLabExportRead(ctx, d, m)
 }
 
-func resourceEveLabExportRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceEveLabExportRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Export is a one-time operation, just verify the lab exists
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := strings.Split(d.Id(), ":export:")[0]
 
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	if err := c.HandleResponse(resp, nil); err != nil {
-		d.SetId("")
-		return nil
+		return handleReadError(d, err, "lab "+labFile)
 	}
 
 	return nil
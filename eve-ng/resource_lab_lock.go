@@ -2,6 +2,7 @@ package eveng
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -23,14 +24,10 @@ func resourceEveLabLock() *schema.Resource {
 }
 
 func resourceEveLabLockCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 
-	resp, err := c.Put("api/labs"+labFile+"/Lock", nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if err := c.HandleResponse(resp, nil); err != nil {
+	if err := setLabLock(ctx, c, labFile, true); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -38,47 +35,52 @@ func resourceEveLabLockCreate(ctx context.Context, d *schema.ResourceData, m int
 	return resourceEveLabLockRead(ctx, d, m)
 }
 
-func resourceEveLabLockRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabLockRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile := strings.TrimSuffix(d.Id(), ":lock")
 
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Lock bool `json:"lock"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
-		d.SetId("")
-		return nil
+	result, err := client.DecodeResponse[labData](resp)
+	if err != nil {
+		return handleReadError(d, err, "lab "+labFile)
 	}
 
 	if err := d.Set("lab_file", labFile); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("locked", result.Data.Lock); err != nil {
+	if err := d.Set("locked", handleLockField(result.Data.Lock)); err != nil {
 		return diag.FromErr(err)
 	}
 	return nil
 }
 
-func resourceEveLabLockDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabLockDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile := strings.TrimSuffix(d.Id(), ":lock")
 
-	resp, err := c.Put("api/labs"+labFile+"/Unlock", nil)
-	if err != nil {
+	if err := setLabLock(ctx, c, labFile, false); err != nil {
 		return diag.FromErr(err)
 	}
+	return nil
+}
+
+// setLabLock locks or unlocks a lab
+func setLabLock(ctx context.Context, c *client.Client, labFile string, locked bool) error {
+	action := "Unlock"
+	if locked {
+		action = "Lock"
+	}
+
+	resp, err := c.PutContext(ctx, "api/labs"+labFile+"/"+action, nil)
+	if err != nil {
+		return fmt.Errorf("failed to %s lab: %w", strings.ToLower(action), err)
+	}
 	if err := c.HandleResponse(resp, nil); err != nil {
-		return diag.FromErr(err)
+		return fmt.Errorf("failed to %s lab: %w", strings.ToLower(action), err)
 	}
 	return nil
 }
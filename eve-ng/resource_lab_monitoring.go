@@ -10,9 +10,23 @@ import (
 )
 
 const (
-	nodeStatusStarted = "started"
+	nodeStatusStopped  = "stopped"
+	nodeStatusStarted  = "started"
+	nodeStatusBuilding = "building"
+	nodeStatusLocked   = "locked"
 )
 
+// labInventoryData is the node and network summary included in a lab read
+type labInventoryData struct {
+	Nodes []struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+	} `json:"nodes"`
+	Networks []struct {
+		ID int `json:"id"`
+	} `json:"networks"`
+}
+
 func resourceEveLabMonitoring() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceEveLabMonitoringCreate,
@@ -35,30 +49,17 @@ func resourceEveLabMonitoring() *schema.Resource {
 }
 
 func resourceEveLabMonitoringCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 
 	// Get lab status for monitoring
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var labResult struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Nodes []struct {
-				ID     int    `json:"id"`
-				Status string `json:"status"`
-			} `json:"nodes"`
-			Networks []struct {
-				ID int `json:"id"`
-			} `json:"networks"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &labResult); err != nil {
+	labResult, err := client.DecodeResponse[labInventoryData](resp)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -87,32 +88,18 @@ func resourceEveLabMonitoringCreate(ctx context.Context, d *schema.ResourceData,
 	return resourceEveLabMonitoringRead(ctx, d, m)
 }
 
-func resourceEveLabMonitoringRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabMonitoringRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile := strings.TrimSuffix(d.Id(), ":monitoring")
 
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var labResult struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Nodes []struct {
-				ID     int    `json:"id"`
-				Status string `json:"status"`
-			} `json:"nodes"`
-			Networks []struct {
-				ID int `json:"id"`
-			} `json:"networks"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &labResult); err != nil {
-		d.SetId("")
-		return nil
+	labResult, err := client.DecodeResponse[labInventoryData](resp)
+	if err != nil {
+		return handleReadError(d, err, "lab "+labFile)
 	}
 
 	// Count running nodes
@@ -26,11 +26,24 @@ func resourceEveLabMove() *schema.Resource {
 }
 
 func resourceEveLabMoveCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 	destPath := d.Get("destination_path").(string)
 	newName := d.Get("new_name").(string)
 
+	newLabFile, err := moveLab(ctx, c, labFile, destPath, newName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Update ID to reflect new location
+	d.SetId(newLabFile + ":move")
+	return resourceEveLabMoveRead(ctx, d, m)
+}
+
+// moveLab relocates a lab to destPath, renaming it when newName is set, and
+// returns the new lab file path
+func moveLab(ctx context.Context, c *client.Client, labFile, destPath, newName string) (string, error) {
 	// Normalize destination path
 	if !strings.HasPrefix(destPath, "/") {
 		destPath = "/" + destPath
@@ -46,15 +59,14 @@ func resourceEveLabMoveCreate(ctx context.Context, d *schema.ResourceData, m int
 		payload["name"] = newName
 	}
 
-	resp, err := c.Put("api/labs"+labFile+"/move", payload)
+	resp, err := c.PutContext(ctx, "api/labs"+labFile+"/move", payload)
 	if err != nil {
-		return diag.FromErr(err)
+		return "", err
 	}
 	if err := c.HandleResponse(resp, nil); err != nil {
-		return diag.FromErr(err)
+		return "", err
 	}
 
-	// Update ID to reflect new location
 	newLabFile := destPath
 	if newName != "" {
 		newLabFile += newName + ".unl"
@@ -65,30 +77,21 @@ func resourceEveLabMoveCreate(ctx context.Context, d *schema.ResourceData, m int
 			newLabFile += parts[len(parts)-1]
 		}
 	}
-	d.SetId(newLabFile + ":move")
-	return resourceEveLabMoveRead(ctx, d, m)
+	return newLabFile, nil
 }
 
-func resourceEveLabMoveRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveLabMoveRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile := strings.TrimSuffix(d.Id(), ":move")
 
-	resp, err := c.Get("api/labs" + labFile)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Filename string `json:"filename"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
-		d.SetId("")
-		return nil
+	result, err := client.DecodeResponse[labData](resp)
+	if err != nil {
+		return handleReadError(d, err, "moved lab "+labFile)
 	}
 
 	// Extract path and name from filename
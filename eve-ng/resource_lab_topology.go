@@ -0,0 +1,902 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// eve_lab_topology describes the nodes, networks and links of a lab in one
+// resource. Objects are matched to the live lab by name, and only objects
+// declared in the resource are ever changed or deleted.
+
+func resourceEveLabTopology() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceEveLabTopologyApply,
+		ReadContext:   resourceEveLabTopologyRead,
+		UpdateContext: resourceEveLabTopologyApply,
+		DeleteContext: resourceEveLabTopologyDelete,
+		CustomizeDiff: resourceEveLabTopologyCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"lab_file": {Type: schema.TypeString, Required: true}, // follows in-place lab renames
+			"node": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name":     {Type: schema.TypeString, Required: true},
+						"type":     {Type: schema.TypeString, Required: true},
+						"template": {Type: schema.TypeString, Required: true},
+						"image":    {Type: schema.TypeString, Optional: true, Computed: true},
+						"icon":     {Type: schema.TypeString, Optional: true, Computed: true},
+						"top":      {Type: schema.TypeInt, Optional: true, Computed: true},
+						"left":     {Type: schema.TypeInt, Optional: true, Computed: true},
+						"cpu":      {Type: schema.TypeInt, Optional: true, Computed: true},
+						"ram":      {Type: schema.TypeInt, Optional: true, Computed: true},
+						"ethernet": {Type: schema.TypeInt, Optional: true, Computed: true},
+						"serial":   {Type: schema.TypeInt, Optional: true, Computed: true},
+						"id":       {Type: schema.TypeInt, Computed: true},
+					},
+				},
+			},
+			"network": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name":       {Type: schema.TypeString, Required: true},
+						"type":       {Type: schema.TypeString, Optional: true, Default: "bridge"},
+						"top":        {Type: schema.TypeInt, Optional: true, Computed: true},
+						"left":       {Type: schema.TypeInt, Optional: true, Computed: true},
+						"visibility": {Type: schema.TypeString, Optional: true, Default: "1"},
+						"id":         {Type: schema.TypeInt, Computed: true},
+					},
+				},
+			},
+			"link": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						// <node>:<interface> or the name of a declared network
+						"a": {Type: schema.TypeString, Required: true},
+						"b": {Type: schema.TypeString, Required: true},
+						// network joining the endpoints; a hidden one is created for node-to-node links
+						"network_id": {Type: schema.TypeInt, Computed: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+// topologyNodeFields are the node attributes the topology manages, besides
+// the name
+var topologyNodeFields = []string{"type", "template", "image", "icon", "top", "left", "cpu", "ram", "ethernet", "serial"}
+
+// topologyNetworkFields are the network attributes the topology manages,
+// besides the name
+var topologyNetworkFields = []string{"type", "top", "left", "visibility"}
+
+// topologySpec is the expanded content of an eve_lab_topology
+type topologySpec struct {
+	nodes    []map[string]interface{}
+	networks []map[string]interface{}
+	links    []topologyLink
+}
+
+// topologyLink is a link between a node interface and a network or another
+// node interface
+type topologyLink struct {
+	a, b topologyEndpoint
+}
+
+// topologyEndpoint is one end of a link; iface is empty for a network
+type topologyEndpoint struct {
+	name  string
+	iface string
+}
+
+func (e topologyEndpoint) isNetwork() bool {
+	return e.iface == ""
+}
+
+func (e topologyEndpoint) String() string {
+	if e.isNetwork() {
+		return e.name
+	}
+	return e.name + ":" + e.iface
+}
+
+func parseTopologyEndpoint(s string) topologyEndpoint {
+	name, iface, _ := strings.Cut(s, ":")
+	return topologyEndpoint{name: name, iface: iface}
+}
+
+var linkNetworkNameInvalid = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// bridgeName is the name of the hidden network created for a node-to-node
+// link, or empty when the link ends on a declared network
+func (l topologyLink) bridgeName() string {
+	if l.a.isNetwork() || l.b.isNetwork() {
+		return ""
+	}
+	return "link_" + linkNetworkNameInvalid.ReplaceAllString(l.a.String()+"_"+l.b.String(), "_")
+}
+
+// networkName is the network the link attaches its node interfaces to
+func (l topologyLink) networkName() string {
+	switch {
+	case l.a.isNetwork():
+		return l.a.name
+	case l.b.isNetwork():
+		return l.b.name
+	default:
+		return l.bridgeName()
+	}
+}
+
+// nodeEnds returns the node interface endpoints of the link
+func (l topologyLink) nodeEnds() []topologyEndpoint {
+	var ends []topologyEndpoint
+	for _, e := range []topologyEndpoint{l.a, l.b} {
+		if !e.isNetwork() {
+			ends = append(ends, e)
+		}
+	}
+	return ends
+}
+
+// expandTopology converts the node, network and link lists into a spec,
+// rejecting duplicate names and links that reference undeclared objects
+func expandTopology(nodes, networks, links []interface{}) (*topologySpec, error) {
+	spec := &topologySpec{}
+	nodeNames := map[string]bool{}
+	for _, raw := range nodes {
+		node := raw.(map[string]interface{})
+		name := node["name"].(string)
+		if nodeNames[name] {
+			return nil, fmt.Errorf("node %q is declared more than once", name)
+		}
+		nodeNames[name] = true
+		spec.nodes = append(spec.nodes, node)
+	}
+
+	networkNames := map[string]bool{}
+	for _, raw := range networks {
+		network := raw.(map[string]interface{})
+		name := network["name"].(string)
+		if networkNames[name] {
+			return nil, fmt.Errorf("network %q is declared more than once", name)
+		}
+		networkNames[name] = true
+		spec.networks = append(spec.networks, network)
+	}
+
+	for _, raw := range links {
+		attrs := raw.(map[string]interface{})
+		link := topologyLink{a: parseTopologyEndpoint(attrs["a"].(string)), b: parseTopologyEndpoint(attrs["b"].(string))}
+		if link.a.isNetwork() && link.b.isNetwork() {
+			return nil, fmt.Errorf("link %s - %s must have at least one node interface end", link.a, link.b)
+		}
+		for _, end := range []topologyEndpoint{link.a, link.b} {
+			if end.isNetwork() && !networkNames[end.name] {
+				return nil, fmt.Errorf("link end %q is not a declared network", end)
+			}
+			if !end.isNetwork() && !nodeNames[end.name] {
+				return nil, fmt.Errorf("link end %q is not on a declared node", end)
+			}
+		}
+		spec.links = append(spec.links, link)
+	}
+	return spec, nil
+}
+
+// liveTopology is the current content of a lab, keyed by object name
+type liveTopology struct {
+	labFile  string
+	nodes    map[string]liveNode
+	networks map[string]liveNetwork
+	ifaces   map[int]*nodeInterfacesData
+}
+
+type liveNode struct {
+	id   int
+	data map[string]interface{}
+}
+
+type liveNetwork struct {
+	id   int
+	data networkData
+}
+
+// fetchLiveTopology reads the lab and its node and network listings
+func fetchLiveTopology(ctx context.Context, c *client.Client, labFile string) (*liveTopology, error) {
+	resp, err := c.GetContext(ctx, "api/labs"+labFile)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.DecodeResponse[labData](resp); err != nil {
+		return nil, err
+	}
+
+	resp, err = c.GetContext(ctx, "api/labs"+labFile+"/nodes")
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := client.DecodeResponse[indexedList[map[string]interface{}]](resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	resp, err = c.GetContext(ctx, "api/labs"+labFile+"/networks")
+	if err != nil {
+		return nil, err
+	}
+	networks, err := client.DecodeResponse[indexedList[networkData]](resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	live := &liveTopology{
+		labFile:  labFile,
+		nodes:    map[string]liveNode{},
+		networks: map[string]liveNetwork{},
+		ifaces:   map[int]*nodeInterfacesData{},
+	}
+	for _, id := range sortedKeys(nodes.Data) {
+		name, _ := nodes.Data[id]["name"].(string)
+		if _, dup := live.nodes[name]; dup {
+			log.Printf("[WARN] Lab %s has more than one node named %q, using the first one", labFile, name)
+			continue
+		}
+		live.nodes[name] = liveNode{id: id, data: nodes.Data[id]}
+	}
+	for _, id := range sortedKeys(networks.Data) {
+		name := networks.Data[id].Name
+		if _, dup := live.networks[name]; dup {
+			log.Printf("[WARN] Lab %s has more than one network named %q, using the first one", labFile, name)
+			continue
+		}
+		live.networks[name] = liveNetwork{id: id, data: networks.Data[id]}
+	}
+	return live, nil
+}
+
+func sortedKeys[T any](m map[int]T) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// interfaces returns the interfaces of a live node, reading them once
+func (l *liveTopology) interfaces(ctx context.Context, c *client.Client, nodeID int) (*nodeInterfacesData, error) {
+	if ifaces, ok := l.ifaces[nodeID]; ok {
+		return ifaces, nil
+	}
+	ifaces, err := getNodeInterfaces(ctx, c, l.labFile, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interfaces of node %d: %w", nodeID, err)
+	}
+	l.ifaces[nodeID] = ifaces
+	return ifaces, nil
+}
+
+// ethernetIndex resolves an interface name of a live node to its index
+func (l *liveTopology) ethernetIndex(ctx context.Context, c *client.Client, end topologyEndpoint) (int, error) {
+	node, ok := l.nodes[end.name]
+	if !ok {
+		return 0, fmt.Errorf("node %q does not exist", end.name)
+	}
+	ifaces, err := l.interfaces(ctx, c, node.id)
+	if err != nil {
+		return 0, err
+	}
+	idx, serial, err := ifaces.interfaceIndex(end.iface)
+	if err != nil {
+		return 0, fmt.Errorf("node %q: %w", end.name, err)
+	}
+	if serial {
+		return 0, fmt.Errorf("link end %s is a serial interface; only ethernet interfaces can be linked", end)
+	}
+	return idx, nil
+}
+
+// liveString and liveInt read loosely typed fields of a listed node
+func liveString(data map[string]interface{}, key string) string {
+	switch v := data[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.Itoa(int(v))
+	default:
+		return ""
+	}
+}
+
+func liveInt(data map[string]interface{}, key string) int {
+	switch v := data[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	default:
+		return 0
+	}
+}
+
+// changedFields returns the desired fields that differ from the live
+// object, or all desired fields when there is no live object yet. Fields
+// left to the server are absent from desired, see dropUnconfigured.
+func changedFields(desired map[string]interface{}, live map[string]interface{}, fields []string) map[string]interface{} {
+	changed := map[string]interface{}{}
+	for _, k := range fields {
+		switch v := desired[k].(type) {
+		case string:
+			if live == nil || v != liveString(live, k) {
+				changed[k] = v
+			}
+		case int:
+			if live == nil || v != liveInt(live, k) {
+				changed[k] = v
+			}
+		}
+	}
+	return changed
+}
+
+// dropUnconfigured removes the optional computed fields that are not set in
+// the raw configuration of a node or network list from its specs, so that
+// configured zero values and empty strings are still applied. Without a raw
+// configuration, zero values are taken as unset.
+func dropUnconfigured(specs []map[string]interface{}, raw cty.Value, elem *schema.Resource) {
+	for i, spec := range specs {
+		for k, attr := range elem.Schema {
+			if !attr.Optional || !attr.Computed {
+				continue
+			}
+			var configured bool
+			if raw.IsNull() || !raw.IsKnown() || raw.LengthInt() <= i {
+				configured = spec[k] != "" && spec[k] != 0
+			} else {
+				configured = !raw.Index(cty.NumberIntVal(int64(i))).GetAttr(k).IsNull()
+			}
+			if !configured {
+				delete(spec, k)
+			}
+		}
+	}
+}
+
+// networkFields flattens a listed network for comparison with its spec
+func networkFields(data networkData) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       data.Type,
+		"top":        float64(data.Top),
+		"left":       float64(data.Left),
+		"visibility": convertVisibilityToString(data.Visibility),
+	}
+}
+
+// topologyApplier applies a topology spec to a lab
+type topologyApplier struct {
+	c       *client.Client
+	labFile string
+	live    *liveTopology
+}
+
+func (a *topologyApplier) path(parts ...string) string {
+	return "api/labs" + a.labFile + "/" + strings.Join(parts, "/")
+}
+
+func (a *topologyApplier) send(ctx context.Context, method, path string, payload map[string]interface{}) error {
+	log.Printf("[DEBUG] Topology %s %s %+v", method, path, payload)
+	var (
+		resp *http.Response
+		err  error
+	)
+	switch method {
+	case http.MethodPut:
+		resp, err = a.c.PutContext(ctx, path, payload)
+	case http.MethodDelete:
+		resp, err = a.c.DeleteContext(ctx, path)
+	default:
+		return fmt.Errorf("unsupported method %s", method)
+	}
+	if err != nil {
+		return err
+	}
+	return a.c.HandleResponse(resp, nil)
+}
+
+// applyNetwork creates the network or updates its changed fields
+func (a *topologyApplier) applyNetwork(ctx context.Context, spec map[string]interface{}) error {
+	name := spec["name"].(string)
+	if existing, ok := a.live.networks[name]; ok {
+		changed := changedFields(spec, networkFields(existing.data), topologyNetworkFields)
+		if len(changed) == 0 {
+			return nil
+		}
+		if err := a.send(ctx, http.MethodPut, a.path("networks", strconv.Itoa(existing.id)), changed); err != nil {
+			return fmt.Errorf("failed to update network %q: %w", name, err)
+		}
+		return nil
+	}
+
+	payload := map[string]interface{}{"name": name}
+	for k, v := range changedFields(spec, nil, topologyNetworkFields) {
+		payload[k] = v
+	}
+	id, err := createNetwork(ctx, a.c, a.labFile, payload)
+	if err != nil {
+		return fmt.Errorf("network %q: %w", name, err)
+	}
+	a.live.networks[name] = liveNetwork{id: id, data: networkData{Name: name}}
+	return nil
+}
+
+// applyNode creates the node or updates its changed fields
+func (a *topologyApplier) applyNode(ctx context.Context, spec map[string]interface{}) error {
+	name := spec["name"].(string)
+	if existing, ok := a.live.nodes[name]; ok {
+		changed := changedFields(spec, existing.data, topologyNodeFields)
+		if len(changed) == 0 {
+			return nil
+		}
+		changed["id"] = existing.id
+		if err := a.send(ctx, http.MethodPut, a.path("nodes", strconv.Itoa(existing.id)), changed); err != nil {
+			return fmt.Errorf("failed to update node %q: %w", name, err)
+		}
+		// Interface lists change with the interface counts
+		delete(a.live.ifaces, existing.id)
+		return nil
+	}
+
+	payload := map[string]interface{}{"name": name}
+	for k, v := range changedFields(spec, nil, topologyNodeFields) {
+		payload[k] = v
+	}
+	id, err := createNode(ctx, a.c, a.labFile, payload)
+	if err != nil {
+		return fmt.Errorf("node %q: %w", name, err)
+	}
+	a.live.nodes[name] = liveNode{id: id, data: map[string]interface{}{"name": name}}
+	return nil
+}
+
+// attach connects the node interfaces of the given links, and disconnects
+// the interfaces of removed links on the kept nodes, with one request per
+// node
+func (a *topologyApplier) attach(ctx context.Context, links, removed []topologyLink, keepNodes map[string]bool) error {
+	want := map[string]map[int]int{}
+	set := func(end topologyEndpoint, networkID int) error {
+		idx, err := a.live.ethernetIndex(ctx, a.c, end)
+		if err != nil {
+			return err
+		}
+		if want[end.name] == nil {
+			want[end.name] = map[int]int{}
+		}
+		want[end.name][idx] = networkID
+		return nil
+	}
+
+	for _, link := range removed {
+		for _, end := range link.nodeEnds() {
+			// Deleted nodes take their connections with them
+			if _, ok := a.live.nodes[end.name]; !ok || !keepNodes[end.name] {
+				continue
+			}
+			if err := set(end, 0); err != nil {
+				log.Printf("[WARN] Not detaching removed link end %s: %v", end, err)
+			}
+		}
+	}
+	for _, link := range links {
+		network, ok := a.live.networks[link.networkName()]
+		if !ok {
+			return fmt.Errorf("network %q for link %s - %s does not exist", link.networkName(), link.a, link.b)
+		}
+		for _, end := range link.nodeEnds() {
+			if err := set(end, network.id); err != nil {
+				return err
+			}
+		}
+	}
+
+	names := make([]string, 0, len(want))
+	for name := range want {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := a.attachNode(ctx, a.live.nodes[name].id, want[name]); err != nil {
+			return fmt.Errorf("failed to connect node %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (a *topologyApplier) attachNode(ctx context.Context, nodeID int, want map[int]int) error {
+	ifaces, err := a.live.interfaces(ctx, a.c, nodeID)
+	if err != nil {
+		return err
+	}
+	payload := map[string]interface{}{}
+	for idx, networkID := range want {
+		if ifaces.Ethernet[idx].NetworkID != networkID {
+			payload[strconv.Itoa(idx)] = networkID
+		}
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	return a.send(ctx, http.MethodPut, a.path("nodes", strconv.Itoa(nodeID), "interfaces"), payload)
+}
+
+// deleteNode and deleteNetwork remove an object if it still exists
+func (a *topologyApplier) deleteNode(ctx context.Context, name string) error {
+	node, ok := a.live.nodes[name]
+	if !ok {
+		return nil
+	}
+	if err := a.send(ctx, http.MethodDelete, a.path("nodes", strconv.Itoa(node.id)), nil); ignoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete node %q: %w", name, err)
+	}
+	delete(a.live.nodes, name)
+	return nil
+}
+
+func (a *topologyApplier) deleteNetwork(ctx context.Context, name string) error {
+	network, ok := a.live.networks[name]
+	if !ok {
+		return nil
+	}
+	if err := a.send(ctx, http.MethodDelete, a.path("networks", strconv.Itoa(network.id)), nil); ignoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete network %q: %w", name, err)
+	}
+	delete(a.live.networks, name)
+	return nil
+}
+
+// networkSpecs returns the declared networks plus a hidden bridge for every
+// node-to-node link
+func (s *topologySpec) networkSpecs() []map[string]interface{} {
+	specs := append([]map[string]interface{}{}, s.networks...)
+	for _, link := range s.links {
+		if name := link.bridgeName(); name != "" {
+			specs = append(specs, map[string]interface{}{"name": name, "type": "bridge", "visibility": "0"})
+		}
+	}
+	return specs
+}
+
+func specNames(specs []map[string]interface{}) map[string]bool {
+	names := map[string]bool{}
+	for _, spec := range specs {
+		names[spec["name"].(string)] = true
+	}
+	return names
+}
+
+func linkKey(l topologyLink) string {
+	return l.a.String() + "|" + l.b.String()
+}
+
+// removedLinks returns the links of old that are not in current
+func removedLinks(old, current []topologyLink) []topologyLink {
+	keep := map[string]bool{}
+	for _, l := range current {
+		keep[linkKey(l)] = true
+	}
+	var removed []topologyLink
+	for _, l := range old {
+		if !keep[linkKey(l)] {
+			removed = append(removed, l)
+		}
+	}
+	return removed
+}
+
+func expandTopologyChange(d *schema.ResourceData) (old, current *topologySpec, err error) {
+	oldNodes, newNodes := d.GetChange("node")
+	oldNetworks, newNetworks := d.GetChange("network")
+	oldLinks, newLinks := d.GetChange("link")
+
+	if old, err = expandTopology(oldNodes.([]interface{}), oldNetworks.([]interface{}), oldLinks.([]interface{})); err != nil {
+		return nil, nil, err
+	}
+	if current, err = expandTopology(newNodes.([]interface{}), newNetworks.([]interface{}), newLinks.([]interface{})); err != nil {
+		return nil, nil, err
+	}
+
+	nodes, networks := cty.NullVal(cty.DynamicPseudoType), cty.NullVal(cty.DynamicPseudoType)
+	if raw := d.GetRawConfig(); !raw.IsNull() {
+		nodes, networks = raw.GetAttr("node"), raw.GetAttr("network")
+	}
+	s := resourceEveLabTopology().Schema
+	dropUnconfigured(current.nodes, nodes, s["node"].Elem.(*schema.Resource))
+	dropUnconfigured(current.networks, networks, s["network"].Elem.(*schema.Resource))
+	return old, current, nil
+}
+
+// apply creates and updates networks, then nodes, then connects links, and
+// finally removes links, nodes and networks that were dropped from the
+// configuration
+func (a *topologyApplier) apply(ctx context.Context, old, current *topologySpec) error {
+	for _, spec := range current.networkSpecs() {
+		if err := a.applyNetwork(ctx, spec); err != nil {
+			return err
+		}
+	}
+	for _, spec := range current.nodes {
+		if err := a.applyNode(ctx, spec); err != nil {
+			return err
+		}
+	}
+	keepNodes := specNames(current.nodes)
+	if err := a.attach(ctx, current.links, removedLinks(old.links, current.links), keepNodes); err != nil {
+		return err
+	}
+
+	for _, spec := range old.nodes {
+		if name := spec["name"].(string); !keepNodes[name] {
+			if err := a.deleteNode(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	keepNetworks := specNames(current.networkSpecs())
+	for _, spec := range old.networkSpecs() {
+		if name := spec["name"].(string); !keepNetworks[name] {
+			if err := a.deleteNetwork(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func resourceEveLabTopologyCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	return customizeLabFileDiff(ctx, d, m.(*providerMeta).client, sameTopology(d))
+}
+
+// sameTopology matches the nodes and networks in state by name and ID,
+// skipping those already missing from the lab (ID 0)
+func sameTopology(d resourceChange) sameLabObject {
+	nodes, _ := d.GetChange("node")
+	networks, _ := d.GetChange("network")
+	return func(ctx context.Context, c *client.Client, labFile string) (bool, error) {
+		live, err := fetchLiveTopology(ctx, c, labFile)
+		if err != nil {
+			return false, err
+		}
+		for _, raw := range nodes.([]interface{}) {
+			node := raw.(map[string]interface{})
+			if id := node["id"].(int); id != 0 {
+				if n, ok := live.nodes[node["name"].(string)]; !ok || n.id != id {
+					return false, nil
+				}
+			}
+		}
+		for _, raw := range networks.([]interface{}) {
+			network := raw.(map[string]interface{})
+			if id := network["id"].(int); id != 0 {
+				if n, ok := live.networks[network["name"].(string)]; !ok || n.id != id {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	}
+}
+
+func resourceEveLabTopologyApply(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := d.Get("lab_file").(string)
+
+	// The lab was renamed or relocated and the topology moved along with it
+	if !d.IsNewResource() && d.HasChange("lab_file") {
+		if err := checkRenamedLabObject(ctx, c, "topology", labFile, sameTopology(d)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	old, current, err := expandTopologyChange(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	live, err := fetchLiveTopology(ctx, c, labFile)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to read lab %s: %w", labFile, err))
+	}
+
+	log.Printf("[DEBUG] Applying topology of lab '%s': %d nodes, %d networks, %d links",
+		labFile, len(current.nodes), len(current.networks), len(current.links))
+	applier := &topologyApplier{c: c, labFile: labFile, live: live}
+	if err := applier.apply(ctx, old, current); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(labFile + ":topology")
+	return resourceEveLabTopologyRead(ctx, d, m)
+}
+
+func resourceEveLabTopologyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := strings.TrimSuffix(d.Id(), ":topology")
+
+	live, err := fetchLiveTopology(ctx, c, labFile)
+	if err != nil {
+		return handleReadError(d, err, "topology of lab "+labFile)
+	}
+
+	if err := d.Set("lab_file", labFile); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("node", flattenTopologyNodes(d.Get("node").([]interface{}), live)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("network", flattenTopologyNetworks(d.Get("network").([]interface{}), live)); err != nil {
+		return diag.FromErr(err)
+	}
+	links, err := flattenTopologyLinks(ctx, c, d.Get("link").([]interface{}), live)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("link", links); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// flattenTopologyNodes refreshes the nodes in state from the live lab. A node
+// that no longer exists keeps its place with only its name, so that the plan
+// recreates it without shifting the blocks that follow.
+func flattenTopologyNodes(nodes []interface{}, live *liveTopology) []interface{} {
+	result := make([]interface{}, 0, len(nodes))
+	for _, raw := range nodes {
+		name := raw.(map[string]interface{})["name"].(string)
+		node, ok := live.nodes[name]
+		if !ok {
+			log.Printf("[WARN] Node %q no longer exists in lab %s", name, live.labFile)
+			result = append(result, map[string]interface{}{"name": name})
+			continue
+		}
+		flat := map[string]interface{}{"name": name, "id": node.id}
+		for _, k := range topologyNodeFields {
+			if k == "type" || k == "template" || k == "image" || k == "icon" {
+				flat[k] = liveString(node.data, k)
+			} else {
+				flat[k] = liveInt(node.data, k)
+			}
+		}
+		result = append(result, flat)
+	}
+	return result
+}
+
+// flattenTopologyNetworks refreshes the networks in state from the live lab,
+// keeping the place of the networks that no longer exist like nodes
+func flattenTopologyNetworks(networks []interface{}, live *liveTopology) []interface{} {
+	result := make([]interface{}, 0, len(networks))
+	for _, raw := range networks {
+		name := raw.(map[string]interface{})["name"].(string)
+		network, ok := live.networks[name]
+		if !ok {
+			log.Printf("[WARN] Network %q no longer exists in lab %s", name, live.labFile)
+			result = append(result, map[string]interface{}{"name": name})
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"name":       name,
+			"type":       network.data.Type,
+			"top":        network.data.Top,
+			"left":       network.data.Left,
+			"visibility": convertVisibilityToString(network.data.Visibility),
+			"id":         network.id,
+		})
+	}
+	return result
+}
+
+// flattenTopologyLinks keeps the links whose node interfaces are still
+// connected to the expected network
+func flattenTopologyLinks(ctx context.Context, c *client.Client, links []interface{}, live *liveTopology) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(links))
+	for _, raw := range links {
+		attrs := raw.(map[string]interface{})
+		link := topologyLink{a: parseTopologyEndpoint(attrs["a"].(string)), b: parseTopologyEndpoint(attrs["b"].(string))}
+
+		connected, networkID, err := linkConnected(ctx, c, link, live)
+		if err != nil {
+			return nil, err
+		}
+		if !connected {
+			log.Printf("[WARN] Link %s - %s is no longer connected in lab %s", link.a, link.b, live.labFile)
+			continue
+		}
+		result = append(result, map[string]interface{}{"a": attrs["a"], "b": attrs["b"], "network_id": networkID})
+	}
+	return result, nil
+}
+
+func linkConnected(ctx context.Context, c *client.Client, link topologyLink, live *liveTopology) (bool, int, error) {
+	network, ok := live.networks[link.networkName()]
+	if !ok {
+		return false, 0, nil
+	}
+	for _, end := range link.nodeEnds() {
+		node, ok := live.nodes[end.name]
+		if !ok {
+			return false, 0, nil
+		}
+		idx, err := live.ethernetIndex(ctx, c, end)
+		if err != nil {
+			log.Printf("[WARN] %v", err)
+			return false, 0, nil
+		}
+		ifaces, err := live.interfaces(ctx, c, node.id)
+		if err != nil {
+			return false, 0, err
+		}
+		if ifaces.Ethernet[idx].NetworkID != network.id {
+			return false, 0, nil
+		}
+	}
+	return true, network.id, nil
+}
+
+func resourceEveLabTopologyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := strings.TrimSuffix(d.Id(), ":topology")
+
+	spec, err := expandTopology(d.Get("node").([]interface{}), d.Get("network").([]interface{}), d.Get("link").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	live, err := fetchLiveTopology(ctx, c, labFile)
+	if err != nil {
+		if client.IsNotFound(err) {
+			return nil
+		}
+		return diag.FromErr(fmt.Errorf("failed to read lab %s: %w", labFile, err))
+	}
+
+	// Deleting the nodes disconnects their interfaces
+	applier := &topologyApplier{c: c, labFile: labFile, live: live}
+	for _, node := range spec.nodes {
+		if err := applier.deleteNode(ctx, node["name"].(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	for _, network := range spec.networkSpecs() {
+		if err := applier.deleteNetwork(ctx, network["name"].(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// eve_link connects two ethernet interfaces through a hidden bridge network
+// that it creates and deletes with the link.
+
+func resourceEveLink() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceEveLinkCreate,
+		ReadContext:   resourceEveLinkRead,
+		UpdateContext: resourceEveLinkUpdate,
+		DeleteContext: resourceEveLinkDelete,
+		CustomizeDiff: resourceEveLinkCustomizeDiff,
+		Importer:      &schema.ResourceImporter{StateContext: resourceEveLinkImport},
+		Schema: map[string]*schema.Schema{
+			"lab_file":  {Type: schema.TypeString, Required: true}, // follows in-place lab renames
+			"a_node_id": {Type: schema.TypeInt, Required: true, ForceNew: true},
+			// interface name, e.g. Gi0/1 or eth3
+			"a_interface":       {Type: schema.TypeString, Required: true},
+			"a_interface_index": {Type: schema.TypeInt, Computed: true},
+			"b_node_id":         {Type: schema.TypeInt, Required: true, ForceNew: true},
+			"b_interface":       {Type: schema.TypeString, Required: true},
+			"b_interface_index": {Type: schema.TypeInt, Computed: true},
+			// name of the bridge network; derived from the endpoints by default
+			"network_name": {Type: schema.TypeString, Optional: true, Computed: true, ForceNew: true},
+			"network_id":   {Type: schema.TypeInt, Computed: true},
+		},
+	}
+}
+
+// linkEnds are the attribute prefixes of the two ends of a link
+var linkEnds = []string{"a", "b"}
+
+func makeLinkID(labFile string, networkID int) string {
+	return labFile + ":link:" + strconv.Itoa(networkID)
+}
+
+func parseLinkID(id string) (labFile string, networkID int, ok bool) {
+	// <lab_file>:link:<network_id>
+	parts := strings.Split(id, ":link:")
+	if len(parts) != 2 {
+		return "", 0, false
+	}
+	nid, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[0], nid, true
+}
+
+// resolveLinkEnd returns the index of the ethernet interface named by an
+// end of the link
+func resolveLinkEnd(ctx context.Context, c *client.Client, d *schema.ResourceData, labFile, end string) (int, error) {
+	nodeID := d.Get(end + "_node_id").(int)
+	name := d.Get(end + "_interface").(string)
+
+	ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read interfaces of node %d: %w", nodeID, err)
+	}
+	ifIndex, serial, err := ifaces.interfaceIndex(name)
+	if err != nil {
+		return 0, err
+	}
+	if serial {
+		return 0, fmt.Errorf("interface %q of node %d is a serial interface; eve_link connects ethernet interfaces", name, nodeID)
+	}
+	return ifIndex, nil
+}
+
+func resourceEveLinkCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	_, networkID, ok := parseLinkID(d.Id())
+	if !ok {
+		return nil
+	}
+	return customizeLabFileDiff(ctx, d, m.(*providerMeta).client, sameLink(d, networkID))
+}
+
+// sameLink matches the bridge network in state by name
+func sameLink(d resourceChange, networkID int) sameLabObject {
+	name, _ := d.GetChange("network_name")
+	return func(ctx context.Context, c *client.Client, labFile string) (bool, error) {
+		resp, err := c.GetContext(ctx, "api/labs"+labFile+"/networks/"+strconv.Itoa(networkID))
+		if err != nil {
+			return false, err
+		}
+		network, err := client.DecodeResponse[networkData](resp)
+		if err != nil {
+			return false, err
+		}
+		return network.Data.Name == name.(string), nil
+	}
+}
+
+func resourceEveLinkCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := d.Get("lab_file").(string)
+
+	if d.Get("a_node_id").(int) == d.Get("b_node_id").(int) && d.Get("a_interface").(string) == d.Get("b_interface").(string) {
+		return diag.Errorf("both ends of the link are interface %q of node %d", d.Get("a_interface"), d.Get("a_node_id"))
+	}
+
+	indexes := map[string]int{}
+	for _, end := range linkEnds {
+		ifIndex, err := resolveLinkEnd(ctx, c, d, labFile, end)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		indexes[end] = ifIndex
+	}
+
+	name := d.Get("network_name").(string)
+	if name == "" {
+		name = fmt.Sprintf("link_%d_%d_%d_%d", d.Get("a_node_id"), indexes["a"], d.Get("b_node_id"), indexes["b"])
+	}
+	log.Printf("[DEBUG] Creating link network '%s' in lab '%s'", name, labFile)
+	networkID, err := createNetwork(ctx, c, labFile, map[string]interface{}{"name": name, "type": "bridge", "visibility": "0"})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, end := range linkEnds {
+		nodeID := d.Get(end + "_node_id").(int)
+		payload := map[string]interface{}{strconv.Itoa(indexes[end]): networkID}
+		if err := putNodeInterfaces(ctx, c, labFile, nodeID, payload); err != nil {
+			// Do not leave a half-connected bridge behind
+			if cleanupErr := deleteLinkNetwork(ctx, c, labFile, networkID); cleanupErr != nil {
+				log.Printf("[WARN] Failed to delete network %d of failed link: %v", networkID, cleanupErr)
+			}
+			return diag.FromErr(fmt.Errorf("failed to connect node %d: %w", nodeID, err))
+		}
+		_ = d.Set(end+"_interface_index", indexes[end])
+	}
+
+	d.SetId(makeLinkID(labFile, networkID))
+	return resourceEveLinkRead(ctx, d, m)
+}
+
+func resourceEveLinkRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile, networkID, ok := parseLinkID(d.Id())
+	if !ok {
+		d.SetId("")
+		return nil
+	}
+
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/networks/"+strconv.Itoa(networkID))
+	if err != nil {
+		return handleReadError(d, err, fmt.Sprintf("network %d of link in lab %s", networkID, labFile))
+	}
+	network, err := client.DecodeResponse[networkData](resp)
+	if err != nil {
+		return handleReadError(d, err, fmt.Sprintf("network %d of link in lab %s", networkID, labFile))
+	}
+
+	_ = d.Set("lab_file", labFile)
+	_ = d.Set("network_name", network.Data.Name)
+	_ = d.Set("network_id", networkID)
+
+	for _, end := range linkEnds {
+		nodeID := d.Get(end + "_node_id").(int)
+		ifIndex := d.Get(end + "_interface_index").(int)
+
+		ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
+		if ignoreNotFound(err) != nil {
+			return diag.FromErr(fmt.Errorf("failed to read interfaces of node %d: %w", nodeID, err))
+		}
+
+		// An end that is no longer on the bridge shows up as an interface
+		// change, which reconnects it
+		var name string
+		if err == nil && ifaces.Ethernet[ifIndex].NetworkID == networkID {
+			name = ifaces.Ethernet[ifIndex].Name
+		} else {
+			log.Printf("[WARN] Interface %d of node %d is no longer connected to link network %d", ifIndex, nodeID, networkID)
+		}
+		_ = d.Set(end+"_interface", name)
+	}
+	return nil
+}
+
+func resourceEveLinkUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile := d.Get("lab_file").(string)
+	_, networkID, ok := parseLinkID(d.Id())
+	if !ok {
+		return diag.Errorf("invalid ID format")
+	}
+
+	// The lab was renamed or relocated and the link moved along with it
+	if d.HasChange("lab_file") {
+		if err := checkRenamedLabObject(ctx, c, fmt.Sprintf("link network %d", networkID), labFile, sameLink(d, networkID)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	for _, end := range linkEnds {
+		if !d.HasChange(end + "_interface") {
+			continue
+		}
+		nodeID := d.Get(end + "_node_id").(int)
+		ifIndex, err := resolveLinkEnd(ctx, c, d, labFile, end)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		payload := map[string]interface{}{strconv.Itoa(ifIndex): networkID}
+		oldIndex := d.Get(end + "_interface_index").(int)
+		if oldIndex != ifIndex {
+			ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
+			if err != nil {
+				return diag.FromErr(fmt.Errorf("failed to read interfaces of node %d: %w", nodeID, err))
+			}
+			if ifaces.Ethernet[oldIndex].NetworkID == networkID {
+				payload[strconv.Itoa(oldIndex)] = 0
+			}
+		}
+
+		log.Printf("[DEBUG] Reconnecting link end %s (node %d): %+v", end, nodeID, payload)
+		if err := putNodeInterfaces(ctx, c, labFile, nodeID, payload); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to connect node %d: %w", nodeID, err))
+		}
+		_ = d.Set(end+"_interface_index", ifIndex)
+	}
+
+	d.SetId(makeLinkID(labFile, networkID))
+	return resourceEveLinkRead(ctx, d, m)
+}
+
+// deleteLinkNetwork deletes the bridge network of a link
+func deleteLinkNetwork(ctx context.Context, c *client.Client, labFile string, networkID int) error {
+	resp, err := c.DeleteContext(ctx, "api/labs"+labFile+"/networks/"+strconv.Itoa(networkID))
+	if err != nil {
+		return err
+	}
+	return ignoreNotFound(c.HandleResponse(resp, nil))
+}
+
+func resourceEveLinkDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	labFile, networkID, ok := parseLinkID(d.Id())
+	if !ok {
+		return diag.Errorf("invalid ID format")
+	}
+
+	// Ends rewired elsewhere are left alone
+	for _, end := range linkEnds {
+		nodeID := d.Get(end + "_node_id").(int)
+		ifIndex := d.Get(end + "_interface_index").(int)
+		ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
+		if err != nil {
+			if client.IsNotFound(err) {
+				continue
+			}
+			return diag.FromErr(fmt.Errorf("failed to read interfaces of node %d: %w", nodeID, err))
+		}
+		if ifaces.Ethernet[ifIndex].NetworkID != networkID {
+			continue
+		}
+		if err := putNodeInterfaces(ctx, c, labFile, nodeID, map[string]interface{}{strconv.Itoa(ifIndex): 0}); ignoreNotFound(err) != nil {
+			return diag.FromErr(fmt.Errorf("failed to disconnect node %d: %w", nodeID, err))
+		}
+	}
+
+	log.Printf("[DEBUG] Deleting link network %d from lab '%s'", networkID, labFile)
+	if err := deleteLinkNetwork(ctx, c, labFile, networkID); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to delete link network: %w", err))
+	}
+	return nil
+}
@@ -18,23 +18,28 @@ func resourceEveNetwork() *schema.Resource {
 		ReadContext:   resourceEveNetworkRead,
 		UpdateContext: resourceEveNetworkUpdate,
 		DeleteContext: resourceEveNetworkDelete,
-		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
-		Schema: map[string]*schema.Schema{
-			"lab_file":   {Type: schema.TypeString, Required: true, ForceNew: true},
-			"name":       {Type: schema.TypeString, Required: true},
-			"type":       {Type: schema.TypeString, Required: true},
-			"top":        {Type: schema.TypeInt, Optional: true, Default: 0},
-			"left":       {Type: schema.TypeInt, Optional: true, Default: 0},
-			"icon":       {Type: schema.TypeString, Optional: true, Default: ""},
-			"visibility": {Type: schema.TypeString, Optional: true, Default: "1"},
-			"id":         {Type: schema.TypeString, Computed: true},
-			"node_count": {Type: schema.TypeInt, Computed: true},
-		},
+		CustomizeDiff: resourceEveNetworkCustomizeDiff,
+		Importer:      &schema.ResourceImporter{StateContext: resourceEveNetworkImport},
+		Schema:        networkSchema(),
+	}
+}
+
+func networkSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"lab_file":   {Type: schema.TypeString, Required: true},
+		"name":       {Type: schema.TypeString, Required: true},
+		"type":       {Type: schema.TypeString, Required: true},
+		"top":        {Type: schema.TypeInt, Optional: true, Default: 0},
+		"left":       {Type: schema.TypeInt, Optional: true, Default: 0},
+		"icon":       {Type: schema.TypeString, Optional: true, Default: ""},
+		"visibility": {Type: schema.TypeString, Optional: true, Default: "1"},
+		"id":         {Type: schema.TypeString, Computed: true},
+		"node_count": {Type: schema.TypeInt, Computed: true},
 	}
 }
 
 func resourceEveNetworkCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 
 	labFile := d.Get("lab_file").(string)
 	networkName := d.Get("name").(string)
@@ -61,32 +66,12 @@ func resourceEveNetworkCreate(ctx context.Context, d *schema.ResourceData, m int
 
 	log.Printf("[DEBUG] Network payload: %+v", payload)
 
-	resp, err := c.Post("api/labs"+labFile+"/networks", payload)
+	id, err := createNetwork(ctx, c, labFile, payload)
 	if err != nil {
-		log.Printf("[ERROR] Failed to create network: %v", err)
-		return diag.FromErr(fmt.Errorf("failed to create network: %w", err))
-	}
-
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			ID int `json:"id"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
-		log.Printf("[ERROR] Failed to handle network creation response: %v", err)
-		return diag.FromErr(fmt.Errorf("failed to handle network creation response: %w", err))
-	}
-
-	if result.Code != 201 {
-		log.Printf("[ERROR] Network creation failed with code %d: %s", result.Code, result.Message)
-		return diag.Errorf("network creation failed: %s", result.Message)
+		return diag.FromErr(err)
 	}
 
 	// ID format: <lab_file>:network:<id>
-	id := result.Data.ID
 	networkID := labFile + ":network:" + strconv.Itoa(id)
 	d.SetId(networkID)
 
@@ -95,6 +80,38 @@ func resourceEveNetworkCreate(ctx context.Context, d *schema.ResourceData, m int
 	return resourceEveNetworkRead(ctx, d, m)
 }
 
+// createNetwork posts the network payload and returns the new network's ID
+func createNetwork(ctx context.Context, c *client.Client, labFile string, payload map[string]interface{}) (int, error) {
+	resp, err := c.PostContext(ctx, "api/labs"+labFile+"/networks", payload)
+	if err != nil {
+		log.Printf("[ERROR] Failed to create network: %v", err)
+		return 0, fmt.Errorf("failed to create network: %w", err)
+	}
+
+	result, err := client.DecodeResponse[networkCreateData](resp)
+	if err != nil {
+		log.Printf("[ERROR] Failed to handle network creation response: %v", err)
+		return 0, fmt.Errorf("failed to handle network creation response: %w", err)
+	}
+	return result.Data.ID, nil
+}
+
+// networkCreateData is the data returned by POST /api/labs/<lab_file>/networks
+type networkCreateData struct {
+	ID int `json:"id"`
+}
+
+// networkData is a network object as returned by the network read and list endpoints
+type networkData struct {
+	Count      int         `json:"count"`
+	Left       int         `json:"left"`
+	Name       string      `json:"name"`
+	Top        int         `json:"top"`
+	Type       string      `json:"type"`
+	Visibility interface{} `json:"visibility"` // Can be string or int
+	Icon       string      `json:"icon"`
+}
+
 func parseNetworkID(id string) (labFile string, netID int, ok bool) {
 	// format: <lab_file>:network:<id>
 	parts := strings.Split(id, ":network:")
@@ -110,7 +127,7 @@ func parseNetworkID(id string) (labFile string, netID int, ok bool) {
 }
 
 func resourceEveNetworkRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile, netID, ok := parseNetworkID(d.Id())
 	if !ok {
 		log.Printf("[ERROR] Invalid network ID format: %s", d.Id())
@@ -121,36 +138,16 @@ func resourceEveNetworkRead(ctx context.Context, d *schema.ResourceData, m inter
 	log.Printf("[DEBUG] Reading network %d from lab '%s'", netID, labFile)
 
 	// Try individual network read first
-	resp, err := c.Get("api/labs" + labFile + "/networks/" + strconv.Itoa(netID))
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/networks/"+strconv.Itoa(netID))
 	if err != nil {
 		log.Printf("[ERROR] Failed to get network: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to get network: %w", err))
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Count      int         `json:"count"`
-			Left       int         `json:"left"`
-			Name       string      `json:"name"`
-			Top        int         `json:"top"`
-			Type       string      `json:"type"`
-			Visibility interface{} `json:"visibility"` // Can be string or int
-			Icon       string      `json:"icon"`
-		} `json:"data"`
-	}
-
 	// Handle individual network read response
-	if err := c.HandleResponse(resp, &result); err != nil {
-		log.Printf("[WARN] Individual network read failed: %v", err)
-		// Fallback to network list read
-		return resourceEveNetworkReadFromList(ctx, d, m, labFile, netID)
-	}
-
-	if result.Code != 200 {
-		log.Printf("[WARN] Individual network read failed with code %d: %s, trying network list", result.Code, result.Message)
+	result, err := client.DecodeResponse[networkData](resp)
+	if err != nil {
+		log.Printf("[WARN] Individual network read failed: %v, trying network list", err)
 		// Fallback to network list read
 		return resourceEveNetworkReadFromList(ctx, d, m, labFile, netID)
 	}
@@ -160,15 +157,7 @@ func resourceEveNetworkRead(ctx context.Context, d *schema.ResourceData, m inter
 }
 
 // setNetworkData sets network data from either individual read or list read
-func setNetworkData(d *schema.ResourceData, labFile string, netID int, data *struct {
-	Count      int         `json:"count"`
-	Left       int         `json:"left"`
-	Name       string      `json:"name"`
-	Top        int         `json:"top"`
-	Type       string      `json:"type"`
-	Visibility interface{} `json:"visibility"`
-	Icon       string      `json:"icon"`
-}, source string) diag.Diagnostics {
+func setNetworkData(d *schema.ResourceData, labFile string, netID int, data *networkData, source string) diag.Diagnostics {
 	// Handle visibility field which can be string or int
 	visibility := convertVisibilityToString(data.Visibility)
 
@@ -218,48 +207,25 @@ func convertVisibilityToString(visibility interface{}) string {
 }
 
 // Fallback function to read network from network list
-func resourceEveNetworkReadFromList(_ context.Context, d *schema.ResourceData, m interface{}, labFile string, netID int) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveNetworkReadFromList(ctx context.Context, d *schema.ResourceData, m interface{}, labFile string, netID int) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
 	log.Printf("[DEBUG] Reading network %d from network list in lab '%s'", netID, labFile)
 
-	resp, err := c.Get("api/labs" + labFile + "/networks")
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/networks")
 	if err != nil {
 		log.Printf("[ERROR] Failed to get network list: %v", err)
-		d.SetId("")
-		return nil
-	}
-
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    map[string]struct {
-			Count      int         `json:"count"`
-			Left       int         `json:"left"`
-			Name       string      `json:"name"`
-			Top        int         `json:"top"`
-			Type       string      `json:"type"`
-			Visibility interface{} `json:"visibility"` // Can be string or int
-			Icon       string      `json:"icon"`
-		} `json:"data"`
-	}
-
-	if err := c.HandleResponse(resp, &result); err != nil {
-		log.Printf("[ERROR] Failed to handle network list response: %v", err)
-		d.SetId("")
-		return nil
+		return diag.FromErr(fmt.Errorf("failed to get network list: %w", err))
 	}
 
-	if result.Code != 200 {
-		log.Printf("[ERROR] Network list read failed with code %d: %s", result.Code, result.Message)
-		d.SetId("")
-		return nil
+	result, err := client.DecodeResponse[map[string]networkData](resp)
+	if err != nil {
+		return handleReadError(d, err, "network list of lab "+labFile)
 	}
 
 	// Look for the specific network ID in the list
 	netIDStr := strconv.Itoa(netID)
-	networkData, exists := result.Data[netIDStr]
+	data, exists := result.Data[netIDStr]
 	if !exists {
 		log.Printf("[ERROR] Network %d not found in network list", netID)
 		d.SetId("")
@@ -267,17 +233,52 @@ func resourceEveNetworkReadFromList(_ context.Context, d *schema.ResourceData, m
 	}
 
 	// Set network data from list read
-	return setNetworkData(d, labFile, netID, &networkData, "list")
+	return setNetworkData(d, labFile, netID, &data, "list")
+}
+
+func resourceEveNetworkCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	_, netID, ok := parseNetworkID(d.Id())
+	if !ok {
+		return nil
+	}
+	return customizeLabFileDiff(ctx, d, m.(*providerMeta).client, sameNetwork(d, netID))
+}
+
+// sameNetwork matches the network in state by name and type
+func sameNetwork(d resourceChange, netID int) sameLabObject {
+	return func(ctx context.Context, c *client.Client, labFile string) (bool, error) {
+		resp, err := c.GetContext(ctx, "api/labs"+labFile+"/networks/"+strconv.Itoa(netID))
+		if err != nil {
+			return false, err
+		}
+		network, err := client.DecodeResponse[networkData](resp)
+		if err != nil {
+			return false, err
+		}
+		return sameNamedObject(d, network.Data.Name, network.Data.Type), nil
+	}
 }
 
 func resourceEveNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile, netID, ok := parseNetworkID(d.Id())
 	if !ok {
 		log.Printf("[ERROR] Invalid network ID format: %s", d.Id())
 		return diag.Errorf("invalid ID format")
 	}
 
+	// The lab was renamed or relocated and the network moved along with it
+	if d.HasChange("lab_file") {
+		labFile = d.Get("lab_file").(string)
+		if err := checkRenamedLabObject(ctx, c, fmt.Sprintf("network %d", netID), labFile, sameNetwork(d, netID)); err != nil {
+			return diag.FromErr(err)
+		}
+		d.SetId(labFile + ":network:" + strconv.Itoa(netID))
+		if !d.HasChangesExcept("lab_file") {
+			return resourceEveNetworkRead(ctx, d, m)
+		}
+	}
+
 	log.Printf("[DEBUG] Updating network %d in lab '%s'", netID, labFile)
 
 	payload := map[string]interface{}{
@@ -304,7 +305,7 @@ func resourceEveNetworkUpdate(ctx context.Context, d *schema.ResourceData, m int
 
 	log.Printf("[DEBUG] Network update payload: %+v", payload)
 
-	resp, err := c.Put("api/labs"+labFile+"/networks/"+strconv.Itoa(netID), payload)
+	resp, err := c.PutContext(ctx, "api/labs"+labFile+"/networks/"+strconv.Itoa(netID), payload)
 	if err != nil {
 		log.Printf("[ERROR] Failed to update network: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to update network: %w", err))
@@ -318,8 +319,8 @@ func resourceEveNetworkUpdate(ctx context.Context, d *schema.ResourceData, m int
 	return resourceEveNetworkRead(ctx, d, m)
 }
 
-func resourceEveNetworkDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveNetworkDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile, netID, ok := parseNetworkID(d.Id())
 	if !ok {
 		log.Printf("[ERROR] Invalid network ID format: %s", d.Id())
@@ -328,12 +329,12 @@ func resourceEveNetworkDelete(_ context.Context, d *schema.ResourceData, m inter
 
 	log.Printf("[DEBUG] Deleting network %d from lab '%s'", netID, labFile)
 
-	resp, err := c.Delete("api/labs" + labFile + "/networks/" + strconv.Itoa(netID))
+	resp, err := c.DeleteContext(ctx, "api/labs"+labFile+"/networks/"+strconv.Itoa(netID))
 	if err != nil {
 		log.Printf("[ERROR] Failed to delete network: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to delete network: %w", err))
 	}
-	if err := c.HandleResponse(resp, nil); err != nil {
+	if err := c.HandleResponse(resp, nil); ignoreNotFound(err) != nil {
 		log.Printf("[ERROR] Failed to handle network delete response: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to handle network delete response: %w", err))
 	}
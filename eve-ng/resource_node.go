@@ -6,6 +6,7 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -18,41 +19,58 @@ func resourceEveNode() *schema.Resource {
 		ReadContext:   resourceEveNodeRead,
 		UpdateContext: resourceEveNodeUpdate,
 		DeleteContext: resourceEveNodeDelete,
-		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
-		Schema:        nodeSchema(),
+		CustomizeDiff: resourceEveNodeCustomizeDiff,
+		Importer:      &schema.ResourceImporter{StateContext: resourceEveNodeImport},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		Schema: nodeSchema(),
 	}
 }
 
 func nodeSchema() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
-		"lab_file": {Type: schema.TypeString, Required: true, ForceNew: true},
+		"lab_file": {Type: schema.TypeString, Required: true},
 		"id":       {Type: schema.TypeString, Computed: true},
 		"name":     {Type: schema.TypeString, Required: true},
 		"type":     {Type: schema.TypeString, Required: true},
 		"template": {Type: schema.TypeString, Required: true},
 		"image":    {Type: schema.TypeString, Optional: true, Default: ""},
-		"icon":     {Type: schema.TypeString, Optional: true, Default: ""},
+		"icon":     {Type: schema.TypeString, Optional: true, Computed: true},
 		"top":      {Type: schema.TypeInt, Optional: true, Default: 0},
 		"left":     {Type: schema.TypeInt, Optional: true, Default: 0},
 		"delay":    {Type: schema.TypeInt, Optional: true, Default: 0},
 		"config":   {Type: schema.TypeString, Optional: true, Default: ""},
-		"ethernet": {Type: schema.TypeInt, Optional: true, Default: 0},
+		"ethernet": {Type: schema.TypeInt, Optional: true, Computed: true},
 		"serial":   {Type: schema.TypeInt, Optional: true, Default: 0},
+		"console":  {Type: schema.TypeString, Optional: true, Computed: true}, // telnet, vnc or rdp
+
+		// startup configuration, kept in state as a SHA-256 hash
+		"startup_config":      {Type: schema.TypeString, Optional: true, ConflictsWith: []string{"startup_config_file"}, StateFunc: startupConfigStateFunc},
+		"startup_config_file": {Type: schema.TypeString, Optional: true, ConflictsWith: []string{"startup_config"}},
+		"startup_config_hash": {Type: schema.TypeString, Computed: true},
 
 		// lifecycle
-		"desired_state":    {Type: schema.TypeString, Optional: true, Default: "stopped"},
+		"desired_state":    {Type: schema.TypeString, Optional: true, Default: nodeStatusStopped},
+		"current_state":    {Type: schema.TypeString, Computed: true},
 		"reboot_on_change": {Type: schema.TypeBool, Optional: true, Default: false},
 		"wipe_on_destroy":  {Type: schema.TypeBool, Optional: true, Default: false},
+		"stop_timeout":     {Type: schema.TypeString, Optional: true, Default: "2m", ValidateFunc: validateDuration},
+		"force_stop":       {Type: schema.TypeBool, Optional: true, Default: false},
+		"wait_for":         nodeWaitForSchema(),
 
 		// qemu-specific
-		"cpu":                {Type: schema.TypeInt, Optional: true, Default: 0},
-		"ram":                {Type: schema.TypeInt, Optional: true, Default: 0},
+		"cpu":                {Type: schema.TypeInt, Optional: true, Computed: true},
+		"ram":                {Type: schema.TypeInt, Optional: true, Computed: true},
 		"cpulimit":           {Type: schema.TypeBool, Optional: true, Default: false},
 		"uuid":               {Type: schema.TypeString, Optional: true, Default: ""},
-		"qemu_version":       {Type: schema.TypeString, Optional: true, Default: ""},
-		"qemu_arch":          {Type: schema.TypeString, Optional: true, Default: ""},
-		"qemu_nic":           {Type: schema.TypeString, Optional: true, Default: ""},
-		"qemu_options":       {Type: schema.TypeString, Optional: true, Default: ""},
+		"qemu_version":       {Type: schema.TypeString, Optional: true, Computed: true},
+		"qemu_arch":          {Type: schema.TypeString, Optional: true, Computed: true},
+		"qemu_nic":           {Type: schema.TypeString, Optional: true, Computed: true},
+		"qemu_options":       {Type: schema.TypeString, Optional: true, Computed: true},
 		"firstmac":           {Type: schema.TypeString, Optional: true, Default: ""},
 		"timos_line":         {Type: schema.TypeString, Optional: true, Default: ""},
 		"timos_license":      {Type: schema.TypeString, Optional: true, Default: ""},
@@ -60,8 +78,69 @@ func nodeSchema() map[string]*schema.Schema {
 	}
 }
 
+// nodeStates maps the numeric status EVE-NG reports for a node to its power state
+var nodeStates = map[int]string{
+	0: nodeStatusStopped,
+	1: nodeStatusBuilding,
+	2: nodeStatusStarted,
+	3: nodeStatusLocked,
+}
+
+// powerStateDrifted reports whether a node's runtime state disagrees with
+// its desired state. A building node counts as started, and a locked node is
+// in transition so it is not treated as drift.
+func powerStateDrifted(desired, current string) bool {
+	switch current {
+	case nodeStatusStopped:
+		return desired != nodeStatusStopped
+	case nodeStatusStarted, nodeStatusBuilding:
+		return desired != nodeStatusStarted
+	default:
+		return false
+	}
+}
+
+// sameNode matches the node in state by name and type
+func sameNode(d resourceChange, nodeID int) sameLabObject {
+	return func(ctx context.Context, c *client.Client, labFile string) (bool, error) {
+		data, err := getNodeData(ctx, c, labFile, nodeID)
+		if err != nil {
+			return false, err
+		}
+		return sameNamedObject(d, liveString(data, "name"), liveString(data, "type")), nil
+	}
+}
+
+// resourceEveNodeCustomizeDiff plans a startup config upload when the
+// configuration differs from the server, and a power state change when the
+// node was started or stopped outside of Terraform
+func resourceEveNodeCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	meta := m.(*providerMeta)
+	if _, nodeID, ok := parseNodeID(d.Id()); ok {
+		if err := customizeLabFileDiff(ctx, d, meta.client, sameNode(d, nodeID)); err != nil {
+			return err
+		}
+	}
+	if err := validateNodeTemplate(ctx, d, meta.client, meta.templates); err != nil {
+		return err
+	}
+	if err := customizeStartupConfigDiff(d); err != nil {
+		return err
+	}
+	if d.Id() == "" {
+		return customizeTemplateDefaultsDiff(ctx, d, meta.client, meta.templates)
+	}
+	desired := d.Get("desired_state").(string)
+	current := d.Get("current_state").(string)
+	if powerStateDrifted(desired, current) {
+		log.Printf("[DEBUG] Node %s is %s but should be %s", d.Id(), current, desired)
+		return d.SetNewComputed("current_state")
+	}
+	return nil
+}
+
 func resourceEveNodeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile := d.Get("lab_file").(string)
 	nodeName := d.Get("name").(string)
 	nodeType := d.Get("type").(string)
@@ -70,66 +149,70 @@ func resourceEveNodeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	log.Printf("[DEBUG] Creating node '%s' of type '%s' with template '%s' in lab '%s'",
 		nodeName, nodeType, nodeTemplate, labFile)
 
-	payload := buildNodePayloadFromState(d, false)
+	payload := buildNodePayloadFromState(d)
+	addTemplateDefaults(ctx, c, m.(*providerMeta).templates, d, payload)
 	log.Printf("[DEBUG] Node payload: %+v", payload)
 
-	resp, err := c.Post("api/labs"+labFile+"/nodes", payload)
+	nodeID, err := createNode(ctx, c, labFile, payload)
 	if err != nil {
-		log.Printf("[ERROR] Failed to create node: %v", err)
-		return diag.FromErr(fmt.Errorf("failed to create node: %w", err))
+		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			ID interface{} `json:"id"`
-		} `json:"data"`
+	setNodeID(d, nodeID, labFile)
+	log.Printf("[DEBUG] Node created with ID: %d", nodeID)
+
+	if err := writeStartupConfig(ctx, c, d, labFile, nodeID); err != nil {
+		return diag.FromErr(err)
 	}
-	if err := c.HandleResponse(resp, &result); err != nil {
-		log.Printf("[ERROR] Failed to handle node creation response: %v", err)
-		return diag.FromErr(fmt.Errorf("failed to handle node creation response: %w", err))
+
+	// converge desired_state
+	if ds, _ := d.Get("desired_state").(string); ds == nodeStatusStarted {
+		log.Printf("[DEBUG] Starting node %d", nodeID)
+		if err := startNodeAndWait(ctx, c, d, labFile, nodeID); err != nil {
+			return diag.FromErr(err)
+		}
 	}
+	return resourceEveNodeRead(ctx, d, m)
+}
+
+// nodeCreateData is the data returned by POST /api/labs/<lab_file>/nodes
+type nodeCreateData struct {
+	ID interface{} `json:"id"`
+}
 
-	if result.Code != 201 {
-		log.Printf("[ERROR] Node creation failed with code %d: %s", result.Code, result.Message)
-		return diag.Errorf("node creation failed: %s", result.Message)
+// createNode adds a node to the lab and returns its ID
+func createNode(ctx context.Context, c *client.Client, labFile string, payload map[string]interface{}) (int, error) {
+	resp, err := c.PostContext(ctx, "api/labs"+labFile+"/nodes", payload)
+	if err != nil {
+		log.Printf("[ERROR] Failed to create node: %v", err)
+		return 0, fmt.Errorf("failed to create node: %w", err)
+	}
+
+	result, err := client.DecodeResponse[nodeCreateData](resp)
+	if err != nil {
+		log.Printf("[ERROR] Failed to handle node creation response: %v", err)
+		return 0, fmt.Errorf("failed to handle node creation response: %w", err)
 	}
 
 	// API may return number or array; handle both
-	var nodeID int
 	switch v := result.Data.ID.(type) {
 	case float64:
-		nodeID = int(v)
+		return int(v), nil
 	case []interface{}:
-		if len(v) > 0 {
-			if f, ok := v[0].(float64); ok {
-				nodeID = int(f)
-			} else {
-				log.Printf("[ERROR] Invalid node ID format in array: %v", v[0])
-				return diag.Errorf("invalid node ID format")
-			}
-		} else {
+		if len(v) == 0 {
 			log.Printf("[ERROR] Empty node ID array")
-			return diag.Errorf("empty node ID array")
+			return 0, fmt.Errorf("empty node ID array")
 		}
+		f, ok := v[0].(float64)
+		if !ok {
+			log.Printf("[ERROR] Invalid node ID format in array: %v", v[0])
+			return 0, fmt.Errorf("invalid node ID format")
+		}
+		return int(f), nil
 	default:
 		log.Printf("[ERROR] Unexpected node ID type: %T", result.Data.ID)
-		return diag.Errorf("unexpected node ID type")
-	}
-
-	setNodeID(d, nodeID, labFile)
-	log.Printf("[DEBUG] Node created with ID: %d", nodeID)
-
-	// converge desired_state
-	if ds, _ := d.Get("desired_state").(string); ds == nodeStatusStarted {
-		log.Printf("[DEBUG] Starting node %d", nodeID)
-		if err := nodePower(ctx, c, labFile, nodeID, "start"); err != nil {
-			log.Printf("[WARN] Failed to start node: %v", err)
-		}
+		return 0, fmt.Errorf("unexpected node ID type")
 	}
-	return resourceEveNodeRead(ctx, d, m)
 }
 
 func setNodeID(d *schema.ResourceData, id int, labFile string) {
@@ -137,20 +220,48 @@ func setNodeID(d *schema.ResourceData, id int, labFile string) {
 	d.SetId(labFile + ":node:" + strconv.Itoa(id))
 }
 
-func buildNodePayloadFromState(d *schema.ResourceData, includeID bool) map[string]interface{} {
+func buildNodePayloadFromState(d *schema.ResourceData) map[string]interface{} {
 	p := map[string]interface{}{
 		"name":     d.Get("name"),
 		"type":     d.Get("type"),
 		"template": d.Get("template"),
 	}
-	if includeID {
-		p["id"] = d.Get("id")
-	}
-	copyIf(d, p, "image", "icon", "top", "left", "delay", "config", "ethernet", "serial")
+	copyIf(d, p, "image", "icon", "top", "left", "delay", "config", "ethernet", "serial", "console")
 	copyIf(d, p, "cpu", "ram", "cpulimit", "uuid", "qemu_version", "qemu_arch", "qemu_nic", "qemu_options", "firstmac", "timos_line", "timos_license", "management_address")
 	return p
 }
 
+// nodeCosmeticFields are node attributes EVE-NG applies to a running node
+var nodeCosmeticFields = []string{"name", "icon", "top", "left"}
+
+// nodeHardwareFields are node attributes that only take effect when the node
+// boots, so a running node has to be restarted to apply them
+var nodeHardwareFields = []string{
+	"type", "template", "image", "delay", "config", "ethernet", "serial", "console",
+	"cpu", "ram", "cpulimit", "uuid", "qemu_version", "qemu_arch", "qemu_nic", "qemu_options",
+	"firstmac", "timos_line", "timos_license", "management_address",
+}
+
+// changedNodeFields returns the node attributes changed in this update,
+// keyed by their API name
+func changedNodeFields(d *schema.ResourceData) map[string]interface{} {
+	changed := map[string]interface{}{}
+	for _, fields := range [][]string{nodeCosmeticFields, nodeHardwareFields} {
+		for _, k := range fields {
+			if d.HasChange(k) {
+				changed[k] = d.Get(k)
+			}
+		}
+	}
+	return changed
+}
+
+// nodeIsRunning reports whether the node was running when it was last read
+func nodeIsRunning(d *schema.ResourceData) bool {
+	state, _ := d.GetChange("current_state")
+	return state == nodeStatusStarted || state == nodeStatusBuilding
+}
+
 func copyIf(d *schema.ResourceData, dst map[string]interface{}, keys ...string) {
 	for _, k := range keys {
 		if v, ok := d.GetOk(k); ok {
@@ -172,8 +283,8 @@ func parseNodeID(id string) (labFile string, nodeID int, ok bool) {
 	return lab, nid, true
 }
 
-func resourceEveNodeRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveNodeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	labFile, nodeID, ok := parseNodeID(d.Id())
 	if !ok {
 		log.Printf("[ERROR] Invalid node ID format: %s", d.Id())
@@ -183,33 +294,29 @@ func resourceEveNodeRead(_ context.Context, d *schema.ResourceData, m interface{
 
 	log.Printf("[DEBUG] Reading node %d from lab '%s'", nodeID, labFile)
 
-	resp, err := c.Get("api/labs" + labFile + "/nodes/" + strconv.Itoa(nodeID))
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID))
 	if err != nil {
 		log.Printf("[ERROR] Failed to get node: %v", err)
 		return diag.FromErr(fmt.Errorf("failed to get node: %w", err))
 	}
 
-	var result struct {
-		Code    int                    `json:"code"`
-		Status  string                 `json:"status"`
-		Message string                 `json:"message"`
-		Data    map[string]interface{} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
-		log.Printf("[ERROR] Failed to handle node read response: %v", err)
-		d.SetId("")
-		return nil
-	}
-
-	if result.Code != 200 {
-		log.Printf("[ERROR] Node read failed with code %d: %s", result.Code, result.Message)
-		d.SetId("")
-		return nil
+	result, err := client.DecodeResponse[map[string]interface{}](resp)
+	if err != nil {
+		return handleReadError(d, err, fmt.Sprintf("node %d in lab %s", nodeID, labFile))
 	}
 
 	// Set node data from response
+	_ = d.Set("lab_file", labFile)
 	setNodeDataFromResponse(d, nodeID, result.Data)
 
+	if startupConfigManaged(d) {
+		config, err := readStartupConfig(ctx, c, labFile, nodeID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		_ = d.Set("startup_config_hash", hashStartupConfig(config))
+	}
+
 	log.Printf("[DEBUG] Node read successfully: %s", result.Data["name"])
 	return nil
 }
@@ -223,6 +330,7 @@ func setNodeDataFromResponse(d *schema.ResourceData, nodeID int, data map[string
 	setStringField(d, data, "type")
 	setStringField(d, data, "image")
 	setStringField(d, data, "icon")
+	setStringField(d, data, "console")
 
 	// Set numeric fields
 	setIntField(d, data, "top")
@@ -243,6 +351,15 @@ func setNodeDataFromResponse(d *schema.ResourceData, nodeID int, data map[string
 	setStringField(d, data, "timos_line")
 	setStringField(d, data, "timos_license")
 	setStringField(d, data, "management_address")
+
+	if status, ok := data["status"].(float64); ok {
+		state, known := nodeStates[int(status)]
+		if !known {
+			log.Printf("[WARN] Unknown status %v for node %d", status, nodeID)
+			state = ""
+		}
+		_ = d.Set("current_state", state)
+	}
 }
 
 func setStringField(d *schema.ResourceData, data map[string]interface{}, field string) {
@@ -252,66 +369,162 @@ func setStringField(d *schema.ResourceData, data map[string]interface{}, field s
 }
 
 func setIntField(d *schema.ResourceData, data map[string]interface{}, field string) {
-	if v, ok := data[field].(float64); ok {
+	switch v := data[field].(type) {
+	case float64:
 		_ = d.Set(field, int(v))
+	case string:
+		// Some EVE-NG versions report numbers as strings
+		if n, err := strconv.Atoi(v); err == nil {
+			_ = d.Set(field, n)
+		}
 	}
 }
 
 func resourceEveNodeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile, nodeID, ok := parseNodeID(d.Id())
 	if !ok {
 		return diag.Errorf("invalid ID format")
 	}
 
-	// manage power if reboot_on_change
-	reboot := d.Get("reboot_on_change").(bool)
-	if reboot {
-		_ = nodePower(ctx, c, labFile, nodeID, "stop")
+	// The lab was renamed or relocated and the node moved along with it
+	if d.HasChange("lab_file") {
+		labFile = d.Get("lab_file").(string)
+		if err := checkRenamedLabObject(ctx, c, fmt.Sprintf("node %d", nodeID), labFile, sameNode(d, nodeID)); err != nil {
+			return diag.FromErr(err)
+		}
+		setNodeID(d, nodeID, labFile)
+		if !d.HasChangesExcept("lab_file") {
+			return resourceEveNodeRead(ctx, d, m)
+		}
 	}
 
-	payload := buildNodePayloadFromState(d, true)
-	resp, err := c.Put("api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID), payload)
-	if err != nil {
-		return diag.FromErr(err)
+	running := nodeIsRunning(d)
+	payload := changedNodeFields(d)
+	if len(payload) > 0 {
+		// Hardware changes only apply on boot, so a running node is restarted
+		if running && d.Get("reboot_on_change").(bool) && d.HasChanges(nodeHardwareFields...) {
+			if err := stopNode(ctx, c, d, labFile, nodeID); err != nil {
+				return diag.FromErr(fmt.Errorf("failed to stop node before reconfiguring it: %w", err))
+			}
+			running = false
+		}
+
+		payload["id"] = nodeID
+		log.Printf("[DEBUG] Updating node %d with %+v", nodeID, payload)
+		resp, err := c.PutContext(ctx, "api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID), payload)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := c.HandleResponse(resp, nil); err != nil {
+			return diag.FromErr(err)
+		}
 	}
-	if err := c.HandleResponse(resp, nil); err != nil {
-		return diag.FromErr(err)
+
+	if d.HasChange("startup_config_hash") {
+		if err := writeStartupConfig(ctx, c, d, labFile, nodeID); err != nil {
+			return diag.FromErr(err)
+		}
+	} else if d.HasChanges("startup_config", "startup_config_file") && !startupConfigManaged(d) {
+		if err := disableStartupConfig(ctx, c, labFile, nodeID); err != nil {
+			return diag.FromErr(err)
+		}
+		_ = d.Set("startup_config_hash", "")
 	}
 
 	// converge desired_state
 	if ds, _ := d.Get("desired_state").(string); ds == nodeStatusStarted {
-		_ = nodePower(ctx, c, labFile, nodeID, "start")
-	} else {
-		_ = nodePower(ctx, c, labFile, nodeID, "stop")
+		if !running {
+			if err := startNodeAndWait(ctx, c, d, labFile, nodeID); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	} else if running {
+		if err := stopNode(ctx, c, d, labFile, nodeID); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 	return resourceEveNodeRead(ctx, d, m)
 }
 
 func resourceEveNodeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	labFile, nodeID, ok := parseNodeID(d.Id())
 	if !ok {
 		return diag.Errorf("invalid ID format")
 	}
 
+	var diags diag.Diagnostics
 	if d.Get("wipe_on_destroy").(bool) {
-		_ = nodePower(ctx, c, labFile, nodeID, "wipe")
+		// Best effort: the node is deleted either way
+		if err := nodePower(ctx, c, labFile, nodeID, "wipe"); ignoreNotFound(err) != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Failed to wipe node %d before deleting it", nodeID),
+				Detail:   err.Error(),
+			})
+		}
 	}
-	resp, err := c.Delete("api/labs" + labFile + "/nodes/" + strconv.Itoa(nodeID))
+	resp, err := c.DeleteContext(ctx, "api/labs"+labFile+"/nodes/"+strconv.Itoa(nodeID))
 	if err != nil {
-		return diag.FromErr(err)
+		return append(diags, diag.FromErr(err)...)
 	}
-	if err := c.HandleResponse(resp, nil); err != nil {
-		return diag.FromErr(err)
+	if err := c.HandleResponse(resp, nil); ignoreNotFound(err) != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+// startNodeAndWait starts the node and, when a wait_for block is configured,
+// blocks until the node is ready
+func startNodeAndWait(ctx context.Context, c *client.Client, d *schema.ResourceData, labFile string, nodeID int) error {
+	cond, err := expandNodeWaitFor(d)
+	if err != nil {
+		return err
+	}
+	if err := nodePower(ctx, c, labFile, nodeID, "start"); err != nil {
+		return err
+	}
+	if cond == nil {
+		return nil
+	}
+	return waitForNode(ctx, c, labFile, nodeID, cond)
+}
+
+// stopNode stops the node and waits up to stop_timeout for it to shut down.
+// With force_stop, a node that ignores the graceful stop is wiped, which
+// powers it off hard.
+func stopNode(ctx context.Context, c *client.Client, d *schema.ResourceData, labFile string, nodeID int) error {
+	timeout, err := time.ParseDuration(d.Get("stop_timeout").(string))
+	if err != nil {
+		return fmt.Errorf("invalid stop_timeout: %w", err)
+	}
+	if err := nodePower(ctx, c, labFile, nodeID, "stop"); err != nil {
+		return err
+	}
+
+	err = waitForNodeState(ctx, c, labFile, nodeID, nodeStatusStopped, timeout)
+	if err == nil {
+		return nil
+	}
+	if !d.Get("force_stop").(bool) {
+		return fmt.Errorf("node %d did not stop within %s: %w", nodeID, timeout, err)
+	}
+
+	log.Printf("[WARN] Node %d did not stop within %s, wiping it", nodeID, timeout)
+	if err := nodePower(ctx, c, labFile, nodeID, "wipe"); err != nil {
+		return err
+	}
+	if err := waitForNodeState(ctx, c, labFile, nodeID, nodeStatusStopped, timeout); err != nil {
+		return fmt.Errorf("node %d did not stop after being wiped: %w", nodeID, err)
 	}
 	return nil
 }
 
-func nodePower(_ context.Context, c *client.Client, labFile string, id int, action string) error {
+func nodePower(ctx context.Context, c *client.Client, labFile string, id int, action string) error {
 	log.Printf("[DEBUG] %s node %d in lab '%s'", action, id, labFile)
 
-	resp, err := c.Get("api/labs" + labFile + "/nodes/" + strconv.Itoa(id) + "/" + action)
+	resp, err := c.GetContext(ctx, "api/labs"+labFile+"/nodes/"+strconv.Itoa(id)+"/"+action)
 	if err != nil {
 		log.Printf("[ERROR] Failed to %s node: %v", action, err)
 		return fmt.Errorf("failed to %s node: %w", action, err)
@@ -25,12 +25,12 @@ func resourceEveSystemConfig() *schema.Resource {
 }
 
 func resourceEveSystemConfigCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 
 	// Apply CPU limit if specified
 	if cpuLimit, ok := d.GetOk("cpu_limit"); ok {
 		payload := map[string]interface{}{"cpulimit": cpuLimit}
-		resp, err := c.Post("api/cpulimit", payload)
+		resp, err := c.PostContext(ctx, "api/cpulimit", payload)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -42,7 +42,7 @@ func resourceEveSystemConfigCreate(ctx context.Context, d *schema.ResourceData,
 	// Apply KSM setting if specified
 	if ksmEnabled, ok := d.GetOk("ksm_enabled"); ok {
 		payload := map[string]interface{}{"ksm": ksmEnabled}
-		resp, err := c.Post("api/ksm", payload)
+		resp, err := c.PostContext(ctx, "api/ksm", payload)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -54,7 +54,7 @@ func resourceEveSystemConfigCreate(ctx context.Context, d *schema.ResourceData,
 	// Apply UKSM setting if specified
 	if uksmEnabled, ok := d.GetOk("uksm_enabled"); ok {
 		payload := map[string]interface{}{"uksm": uksmEnabled}
-		resp, err := c.Post("api/uksm", payload)
+		resp, err := c.PostContext(ctx, "api/uksm", payload)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -67,31 +67,25 @@ func resourceEveSystemConfigCreate(ctx context.Context, d *schema.ResourceData,
 	return resourceEveSystemConfigRead(ctx, d, m)
 }
 
-func resourceEveSystemConfigRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveSystemConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 
-	resp, err := c.Get("api/status")
+	resp, err := c.GetContext(ctx, "api/status")
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			CPULimit int `json:"cpu_limit"`
-			KSM      struct {
-				Enabled bool `json:"enabled"`
-			} `json:"ksm"`
-			UKSM struct {
-				Enabled bool `json:"enabled"`
-			} `json:"uksm"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
-		d.SetId("")
-		return nil
+	result, err := client.DecodeResponse[struct {
+		CPULimit int `json:"cpu_limit"`
+		KSM      struct {
+			Enabled bool `json:"enabled"`
+		} `json:"ksm"`
+		UKSM struct {
+			Enabled bool `json:"enabled"`
+		} `json:"uksm"`
+	}](resp)
+	if err != nil {
+		return handleReadError(d, err, "system status")
 	}
 
 	if err := d.Set("cpu_limit", result.Data.CPULimit); err != nil {
@@ -110,13 +104,13 @@ func resourceEveSystemConfigRead(_ context.Context, d *schema.ResourceData, m in
 }
 
 func resourceEveSystemConfigUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 
 	// Update CPU limit if changed
 	if d.HasChange("cpu_limit") {
 		cpuLimit := d.Get("cpu_limit").(int)
 		payload := map[string]interface{}{"cpulimit": cpuLimit}
-		resp, err := c.Post("api/cpulimit", payload)
+		resp, err := c.PostContext(ctx, "api/cpulimit", payload)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -129,7 +123,7 @@ func resourceEveSystemConfigUpdate(ctx context.Context, d *schema.ResourceData,
 	if d.HasChange("ksm_enabled") {
 		ksmEnabled := d.Get("ksm_enabled").(bool)
 		payload := map[string]interface{}{"ksm": ksmEnabled}
-		resp, err := c.Post("api/ksm", payload)
+		resp, err := c.PostContext(ctx, "api/ksm", payload)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -142,7 +136,7 @@ func resourceEveSystemConfigUpdate(ctx context.Context, d *schema.ResourceData,
 	if d.HasChange("uksm_enabled") {
 		uksmEnabled := d.Get("uksm_enabled").(bool)
 		payload := map[string]interface{}{"uksm": uksmEnabled}
-		resp, err := c.Post("api/uksm", payload)
+		resp, err := c.PostContext(ctx, "api/uksm", payload)
 		if err != nil {
 			return diag.FromErr(err)
 		}
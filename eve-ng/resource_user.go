@@ -29,7 +29,7 @@ func resourceEveUser() *schema.Resource {
 }
 
 func resourceEveUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 
 	payload := map[string]interface{}{
 		"username": d.Get("username").(string),
@@ -51,7 +51,7 @@ func resourceEveUserCreate(ctx context.Context, d *schema.ResourceData, m interf
 		payload["expires"] = v
 	}
 
-	resp, err := c.Post("api/users", payload)
+	resp, err := c.PostContext(ctx, "api/users", payload)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -64,31 +64,25 @@ func resourceEveUserCreate(ctx context.Context, d *schema.ResourceData, m interf
 	return resourceEveUserRead(ctx, d, m)
 }
 
-func resourceEveUserRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	username := d.Id()
 
-	resp, err := c.Get("api/users/" + username)
+	resp, err := c.GetContext(ctx, "api/users/"+username)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	var result struct {
-		Code    int    `json:"code"`
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Data    struct {
-			Username string `json:"username"`
-			Email    string `json:"email"`
-			Name     string `json:"name"`
-			Role     string `json:"role"`
-			Enabled  bool   `json:"enabled"`
-			Expires  string `json:"expires"`
-		} `json:"data"`
-	}
-	if err := c.HandleResponse(resp, &result); err != nil {
-		d.SetId("")
-		return nil
+	result, err := client.DecodeResponse[struct {
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Name     string `json:"name"`
+		Role     string `json:"role"`
+		Enabled  bool   `json:"enabled"`
+		Expires  string `json:"expires"`
+	}](resp)
+	if err != nil {
+		return handleReadError(d, err, "user "+username)
 	}
 
 	if err := d.Set("username", result.Data.Username); err != nil {
@@ -116,7 +110,7 @@ func resourceEveUserRead(_ context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourceEveUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	username := d.Id()
 
 	payload := map[string]interface{}{}
@@ -139,7 +133,7 @@ func resourceEveUserUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		payload["expires"] = d.Get("expires").(string)
 	}
 
-	resp, err := c.Put("api/users/"+username, payload)
+	resp, err := c.PutContext(ctx, "api/users/"+username, payload)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -149,15 +143,15 @@ func resourceEveUserUpdate(ctx context.Context, d *schema.ResourceData, m interf
 	return resourceEveUserRead(ctx, d, m)
 }
 
-func resourceEveUserDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+func resourceEveUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
 	username := d.Id()
 
-	resp, err := c.Delete("api/users/" + username)
+	resp, err := c.DeleteContext(ctx, "api/users/"+username)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if err := c.HandleResponse(resp, nil); err != nil {
+	if err := c.HandleResponse(resp, nil); ignoreNotFound(err) != nil {
 		return diag.FromErr(err)
 	}
 	return nil
@@ -0,0 +1,159 @@
+package eveng
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// serialPeer is the far end of a serial connection
+type serialPeer struct {
+	nodeID  int
+	ifIndex int
+	// hasIf is false for the short node:<id> target, where EVE-NG picks the
+	// remote interface
+	hasIf bool
+}
+
+// value is the interface value EVE-NG expects for a serial connection
+func (p serialPeer) value() string {
+	if !p.hasIf {
+		return strconv.Itoa(p.nodeID)
+	}
+	return strconv.Itoa(p.nodeID) + ":" + strconv.Itoa(p.ifIndex)
+}
+
+// parseSerialTarget parses a node:<remote_node_id>[:<remote_if>] target
+func parseSerialTarget(target string) (serialPeer, error) {
+	parts := strings.Split(strings.TrimPrefix(target, "node:"), ":")
+	if !strings.HasPrefix(target, "node:") || len(parts) > 2 {
+		return serialPeer{}, fmt.Errorf("invalid serial target %q, expected node:<remote_node_id>[:<remote_if>]", target)
+	}
+	var peer serialPeer
+	var err error
+	if peer.nodeID, err = strconv.Atoi(parts[0]); err != nil {
+		return serialPeer{}, fmt.Errorf("invalid remote node ID in target %q", target)
+	}
+	if len(parts) == 2 {
+		if peer.ifIndex, err = strconv.Atoi(parts[1]); err != nil {
+			return serialPeer{}, fmt.Errorf("invalid remote interface in target %q", target)
+		}
+		peer.hasIf = true
+	}
+	return peer, nil
+}
+
+// serialInterfaces returns the interfaces of a node after checking that the
+// node has serial interfaces at all
+func serialInterfaces(ctx context.Context, c *client.Client, labFile string, nodeID int) (*nodeInterfacesData, error) {
+	data, err := getNodeData(ctx, c, labFile, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node %d: %w", nodeID, err)
+	}
+	if liveInt(data, "serial") == 0 {
+		return nil, fmt.Errorf("node %d has no serial interfaces (serial = 0)", nodeID)
+	}
+	ifaces, err := getNodeInterfaces(ctx, c, labFile, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interfaces of node %d: %w", nodeID, err)
+	}
+	return ifaces, nil
+}
+
+// requireSerial fails unless the interface is a serial interface of the node
+func requireSerial(ifaces *nodeInterfacesData, ifIndex int) error {
+	if _, ok := ifaces.Serial[ifIndex]; ok {
+		return nil
+	}
+	var valid []string
+	for _, idx := range sortedKeys(ifaces.Serial) {
+		valid = append(valid, fmt.Sprintf("%d (%s)", idx, ifaces.Serial[idx].Name))
+	}
+	return fmt.Errorf("interface %d of node %d is not a serial interface (serial interfaces: %s)", ifIndex, ifaces.ID, strings.Join(valid, ", "))
+}
+
+// configuredSerialPeer returns the serial peer set by remote_node_id and
+// remote_interface, or false when the attachment uses target instead
+func configuredSerialPeer(ctx context.Context, c *client.Client, d *schema.ResourceData, labFile string) (serialPeer, bool, error) {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || raw.GetAttr("remote_node_id").IsNull() {
+		return serialPeer{}, false, nil
+	}
+
+	peer := serialPeer{nodeID: d.Get("remote_node_id").(int), hasIf: true}
+	ifaces, err := serialInterfaces(ctx, c, labFile, peer.nodeID)
+	if err != nil {
+		return serialPeer{}, false, err
+	}
+	name := d.Get("remote_interface").(string)
+	idx, serial, err := ifaces.interfaceIndex(name)
+	if err != nil {
+		return serialPeer{}, false, err
+	}
+	if !serial {
+		return serialPeer{}, false, fmt.Errorf("remote_interface %q of node %d is not a serial interface", name, peer.nodeID)
+	}
+	peer.ifIndex = idx
+	return peer, true, nil
+}
+
+// connectSerial links a serial interface to its peer on both nodes, after
+// checking that both ends are serial interfaces
+func connectSerial(ctx context.Context, c *client.Client, labFile string, nodeID, ifIndex int, peer serialPeer) error {
+	local, err := serialInterfaces(ctx, c, labFile, nodeID)
+	if err != nil {
+		return err
+	}
+	if err := requireSerial(local, ifIndex); err != nil {
+		return err
+	}
+	if peer.nodeID == nodeID && peer.hasIf && peer.ifIndex == ifIndex {
+		return fmt.Errorf("serial interface %d of node %d cannot be linked to itself", ifIndex, nodeID)
+	}
+
+	remote, err := serialInterfaces(ctx, c, labFile, peer.nodeID)
+	if err != nil {
+		return err
+	}
+	if peer.hasIf {
+		if err := requireSerial(remote, peer.ifIndex); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[DEBUG] Linking serial interface %d of node %d to %s", ifIndex, nodeID, peer.value())
+	if err := putNodeInterfaces(ctx, c, labFile, nodeID, map[string]interface{}{strconv.Itoa(ifIndex): peer.value()}); err != nil {
+		return fmt.Errorf("failed to connect node %d: %w", nodeID, err)
+	}
+	if !peer.hasIf {
+		return nil
+	}
+	back := serialPeer{nodeID: nodeID, ifIndex: ifIndex, hasIf: true}
+	if err := putNodeInterfaces(ctx, c, labFile, peer.nodeID, map[string]interface{}{strconv.Itoa(peer.ifIndex): back.value()}); err != nil {
+		return fmt.Errorf("failed to connect node %d: %w", peer.nodeID, err)
+	}
+	return nil
+}
+
+// disconnectSerialPeer disconnects the remote end of a serial link if it
+// still points back at the given interface
+func disconnectSerialPeer(ctx context.Context, c *client.Client, labFile string, nodeID, ifIndex int, peer serialPeer) error {
+	if !peer.hasIf {
+		return nil
+	}
+	remote, err := getNodeInterfaces(ctx, c, labFile, peer.nodeID)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	serial, ok := remote.Serial[peer.ifIndex]
+	if !ok || serial.RemoteID == nil || *serial.RemoteID != nodeID || serial.RemoteIf == nil || *serial.RemoteIf != ifIndex {
+		return nil
+	}
+	log.Printf("[DEBUG] Disconnecting serial interface %d of node %d", peer.ifIndex, peer.nodeID)
+	return ignoreNotFound(putNodeInterfaces(ctx, c, labFile, peer.nodeID, map[string]interface{}{strconv.Itoa(peer.ifIndex): 0}))
+}
@@ -0,0 +1,296 @@
+package eveng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// templateDetails is the data returned by GET /api/list/templates/<template>
+type templateDetails struct {
+	Type        string                    `json:"type"`
+	Description string                    `json:"description"`
+	Options     map[string]templateOption `json:"options"`
+}
+
+// templateOption is a node setting offered by a template, with its default
+// value and, for list settings such as image, the allowed values
+type templateOption struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+	List  optionList  `json:"list"`
+}
+
+// optionList holds the allowed values of a list option, keyed by value.
+// EVE-NG returns an object of value to label, or an empty array.
+type optionList map[string]string
+
+func (l *optionList) UnmarshalJSON(b []byte) error {
+	var keyed map[string]interface{}
+	if err := json.Unmarshal(b, &keyed); err == nil {
+		*l = make(optionList, len(keyed))
+		for k, v := range keyed {
+			(*l)[k] = fmt.Sprint(v)
+		}
+		return nil
+	}
+
+	var list []interface{}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*l = make(optionList, len(list))
+	for _, v := range list {
+		(*l)[fmt.Sprint(v)] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+// values returns the allowed values in version order, so that
+// linux-ubuntu-9.04 sorts before linux-ubuntu-22.04
+func (l optionList) values() []string {
+	values := make([]string, 0, len(l))
+	for v := range l {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return versionLess(values[i], values[j]) })
+	return values
+}
+
+// versionLess compares strings piecewise, comparing runs of digits as numbers
+func versionLess(a, b string) bool {
+	for a != "" && b != "" {
+		pa, pb := leadingRun(a), leadingRun(b)
+		a, b = a[len(pa):], b[len(pb):]
+		if pa == pb {
+			continue
+		}
+		na, errA := strconv.Atoi(pa)
+		nb, errB := strconv.Atoi(pb)
+		if errA == nil && errB == nil && na != nb {
+			return na < nb
+		}
+		return pa < pb
+	}
+	return len(a) < len(b)
+}
+
+// leadingRun returns the leading run of digits or non-digits of s
+func leadingRun(s string) string {
+	digit := func(r byte) bool { return r >= '0' && r <= '9' }
+	i := 1
+	for i < len(s) && digit(s[i]) == digit(s[0]) {
+		i++
+	}
+	return s[:i]
+}
+
+// images returns the images installed for the template
+func (t *templateDetails) images() []string {
+	return t.Options["image"].List.values()
+}
+
+// intOption returns the default of a numeric option, or 0
+func (t *templateDetails) intOption(key string) int {
+	n, _ := strconv.Atoi(t.stringOption(key))
+	return n
+}
+
+// stringOption returns the default of an option, or ""
+func (t *templateDetails) stringOption(key string) string {
+	if v := t.Options[key].Value; v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// templateCatalog caches the template list and template details of an
+// EVE-NG server for the lifetime of a provider instance
+type templateCatalog struct {
+	mu sync.Mutex
+	// templates maps template names to their list entry; nil until loaded
+	templates map[string]interface{}
+	// unavailable is set when the server has no template catalog
+	unavailable bool
+	details     map[string]*templateDetails
+}
+
+func newTemplateCatalog() *templateCatalog {
+	return &templateCatalog{details: map[string]*templateDetails{}}
+}
+
+// list returns the templates known to the server, or nil when the server
+// does not provide a template catalog
+func (t *templateCatalog) list(ctx context.Context, c *client.Client) (map[string]interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.templates != nil || t.unavailable {
+		return t.templates, nil
+	}
+
+	resp, err := c.GetContext(ctx, "api/list/templates/")
+	if err == nil {
+		var result *client.Response[map[string]interface{}]
+		if result, err = client.DecodeResponse[map[string]interface{}](resp); err == nil {
+			t.templates = result.Data
+			return t.templates, nil
+		}
+	}
+	if client.IsNotFound(err) {
+		log.Printf("[WARN] Server has no template catalog, skipping template validation")
+		t.unavailable = true
+		return nil, nil
+	}
+	return nil, fmt.Errorf("failed to list templates: %w", err)
+}
+
+// template returns the details of a template
+func (t *templateCatalog) template(ctx context.Context, c *client.Client, name string) (*templateDetails, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if details, ok := t.details[name]; ok {
+		return details, nil
+	}
+
+	resp, err := c.GetContext(ctx, "api/list/templates/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template %q: %w", name, err)
+	}
+	result, err := client.DecodeResponse[templateDetails](resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template %q: %w", name, err)
+	}
+	t.details[name] = &result.Data
+	return &result.Data, nil
+}
+
+// validateNodeTemplate rejects unknown templates and images, and templates
+// of a different node type, before the node is created or changed
+func validateNodeTemplate(ctx context.Context, d *schema.ResourceDiff, c *client.Client, catalog *templateCatalog) error {
+	if d.Id() != "" && !d.HasChanges("type", "template", "image") {
+		return nil
+	}
+	if !d.NewValueKnown("type") || !d.NewValueKnown("template") || !d.NewValueKnown("image") {
+		return nil
+	}
+
+	templates, err := catalog.list(ctx, c)
+	if err != nil || templates == nil {
+		return err
+	}
+
+	name := d.Get("template").(string)
+	if _, ok := templates[name]; !ok {
+		return fmt.Errorf("unknown template %q; the eve_templates data source lists the templates available on this server", name)
+	}
+	details, err := catalog.template(ctx, c, name)
+	if err != nil {
+		return err
+	}
+
+	nodeType := d.Get("type").(string)
+	if details.Type != "" && details.Type != nodeType {
+		return fmt.Errorf("template %q is a %s template, but type is %q", name, details.Type, nodeType)
+	}
+
+	image := d.Get("image").(string)
+	images := details.Options["image"].List
+	if image == "" || len(images) == 0 {
+		return nil
+	}
+	if _, ok := images[image]; !ok {
+		return fmt.Errorf("image %q is not available for template %q (available: %s)", image, name, strings.Join(details.images(), ", "))
+	}
+	return nil
+}
+
+// nodeTemplateDefaults are the eve_node attributes filled from the
+// template's defaults when they are not configured
+var nodeTemplateDefaults = []string{"icon", "cpu", "ram", "ethernet", "console", "qemu_version", "qemu_arch", "qemu_nic", "qemu_options"}
+
+// nodeTemplateIntDefaults are the integer attributes among them
+var nodeTemplateIntDefaults = map[string]bool{"cpu": true, "ram": true, "ethernet": true}
+
+// defaultValue returns the template's default for a node attribute,
+// converted to the attribute's type
+func (t *templateDetails) defaultValue(key string) (interface{}, bool) {
+	if t.Options[key].Value == nil {
+		return nil, false
+	}
+	value := t.stringOption(key)
+	if !nodeTemplateIntDefaults[key] {
+		return value, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, false
+	}
+	return n, true
+}
+
+// unsetTemplateDefaults returns the template defaults of the attributes not
+// set in raw, or nil when the template details are not available
+func unsetTemplateDefaults(ctx context.Context, c *client.Client, catalog *templateCatalog, raw cty.Value, template string) map[string]interface{} {
+	templates, err := catalog.list(ctx, c)
+	if err != nil || templates == nil {
+		return nil
+	}
+	if _, ok := templates[template]; !ok {
+		return nil
+	}
+	details, err := catalog.template(ctx, c, template)
+	if err != nil {
+		log.Printf("[WARN] Not applying defaults of template %q: %v", template, err)
+		return nil
+	}
+
+	defaults := map[string]interface{}{}
+	for _, k := range nodeTemplateDefaults {
+		if !raw.GetAttr(k).IsNull() {
+			continue
+		}
+		if v, ok := details.defaultValue(k); ok {
+			defaults[k] = v
+		}
+	}
+	return defaults
+}
+
+// customizeTemplateDefaultsDiff plans the template's defaults for the
+// attributes of a new node that are not configured
+func customizeTemplateDefaultsDiff(ctx context.Context, d *schema.ResourceDiff, c *client.Client, catalog *templateCatalog) error {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !d.NewValueKnown("template") {
+		return nil
+	}
+	for k, v := range unsetTemplateDefaults(ctx, c, catalog, raw, d.Get("template").(string)) {
+		if err := d.SetNew(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTemplateDefaults fills the create payload with the template's defaults
+// that could not be planned, e.g. because the template was not known yet
+func addTemplateDefaults(ctx context.Context, c *client.Client, catalog *templateCatalog, d *schema.ResourceData, payload map[string]interface{}) {
+	raw := d.GetRawConfig()
+	if raw.IsNull() {
+		return
+	}
+	for k, v := range unsetTemplateDefaults(ctx, c, catalog, raw, d.Get("template").(string)) {
+		if _, ok := payload[k]; !ok {
+			payload[k] = v
+		}
+	}
+}
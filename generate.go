@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	eveng "github.com/nawada0615/terraform-provider-eve-ng/eve-ng"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+const generateUsage = `Usage: terraform-provider-eve-ng generate -lab <lab_file> [options]
+
+Writes Terraform configuration and import blocks for an existing lab.
+The connection settings default to the provider's EVE_NG_* environment variables.
+
+Options:
+`
+
+// generate implements the generate subcommand
+func generate(args []string) error {
+	insecure, _ := strconv.ParseBool(os.Getenv("EVE_NG_INSECURE"))
+
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), generateUsage)
+		flags.PrintDefaults()
+	}
+	labFile := flags.String("lab", "", "lab file to generate configuration for, e.g. /folder/lab.unl")
+	out := flags.String("out", "", "file to write the configuration to (default stdout)")
+	config := &client.Config{RetryMax: 3, RetryWaitMin: time.Second, RetryWaitMax: 30 * time.Second}
+	flags.StringVar(&config.Endpoint, "endpoint", os.Getenv("EVE_NG_ENDPOINT"), "EVE-NG server URL")
+	flags.StringVar(&config.Username, "username", os.Getenv("EVE_NG_USERNAME"), "EVE-NG username")
+	flags.StringVar(&config.Password, "password", os.Getenv("EVE_NG_PASSWORD"), "EVE-NG password")
+	flags.BoolVar(&config.InsecureSkipVerify, "insecure", insecure, "skip TLS certificate verification")
+	flags.DurationVar(&config.Timeout, "timeout", 30*time.Second, "API request timeout")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if *labFile == "" || config.Endpoint == "" || config.Username == "" || config.Password == "" {
+		flags.Usage()
+		return fmt.Errorf("-lab, -endpoint, -username and -password are required")
+	}
+
+	c, err := client.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create EVE-NG client: %w", err)
+	}
+
+	if *out == "" {
+		return eveng.GenerateConfig(context.Background(), c, *labFile, os.Stdout)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := eveng.GenerateConfig(context.Background(), c, *labFile, f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -3,22 +3,30 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 )
 
+const sessionCookieName = "unetlab_session"
+
 // Client represents the EVE-NG API client
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
-	session    string
 	username   string
 	password   string
+	retry      retryPolicy
+
+	// mu guards session, which is replaced whenever the client re-authenticates
+	mu      sync.Mutex
+	session string
 }
 
 // Config holds the client configuration
@@ -28,6 +36,12 @@ type Config struct {
 	Password           string
 	InsecureSkipVerify bool
 	Timeout            time.Duration
+
+	// RetryMax is the number of times a failed request is retried
+	RetryMax int
+	// RetryWaitMin and RetryWaitMax bound the exponential backoff between retries
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
 }
 
 // NewClient creates a new EVE-NG API client
@@ -57,6 +71,7 @@ func NewClient(config *Config) (*Client, error) {
 		httpClient: httpClient,
 		username:   config.Username,
 		password:   config.Password,
+		retry:      newRetryPolicy(config),
 	}
 
 	// Authenticate on creation
@@ -67,8 +82,30 @@ func NewClient(config *Config) (*Client, error) {
 	return client, nil
 }
 
+// Host returns the host name of the EVE-NG endpoint, which also serves the
+// node consoles
+func (c *Client) Host() string {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
 // Login authenticates with the EVE-NG API
 func (c *Client) Login() error {
+	return c.LoginContext(context.Background())
+}
+
+// LoginContext authenticates with the EVE-NG API, aborting when ctx is done
+func (c *Client) LoginContext(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.login(ctx)
+}
+
+// login performs the authentication request; callers must hold c.mu
+func (c *Client) login(ctx context.Context) error {
 	loginData := map[string]interface{}{
 		"username": c.username,
 		"password": c.password,
@@ -80,7 +117,7 @@ func (c *Client) Login() error {
 		return fmt.Errorf("failed to marshal login data: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+"api/auth/login", bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"api/auth/login", bytes.NewBuffer(reqBody))
 	if err != nil {
 		return fmt.Errorf("failed to create login request: %w", err)
 	}
@@ -112,20 +149,41 @@ func (c *Client) Login() error {
 	}
 
 	// Extract session cookie
+	session := ""
 	for _, cookie := range resp.Cookies() {
-		if cookie.Name == "unetlab_session" {
-			c.session = cookie.Value
+		if cookie.Name == sessionCookieName {
+			session = cookie.Value
 			break
 		}
 	}
 
-	if c.session == "" {
+	if session == "" {
 		return fmt.Errorf("no session cookie received")
 	}
 
+	c.session = session
 	return nil
 }
 
+// currentSession returns the session cookie value in use
+func (c *Client) currentSession() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// reauthenticate logs in again unless another request already replaced the
+// stale session while we were waiting for the lock
+func (c *Client) reauthenticate(ctx context.Context, stale string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.session != stale {
+		return nil
+	}
+	return c.login(ctx)
+}
+
 // Logout logs out from the EVE-NG API
 func (c *Client) Logout() error {
 	req, err := http.NewRequest("GET", c.baseURL+"api/auth/logout", http.NoBody)
@@ -133,12 +191,11 @@ func (c *Client) Logout() error {
 		return fmt.Errorf("failed to create logout request: %w", err)
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	// Add session cookie
-	req.AddCookie(&http.Cookie{
-		Name:  "unetlab_session",
-		Value: c.session,
-		Path:  "/api/",
-	})
+	setSessionCookie(req, c.session)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -150,21 +207,107 @@ func (c *Client) Logout() error {
 	return nil
 }
 
-// Do performs an HTTP request with session authentication
+// Do performs an HTTP request with session authentication.
+// If the server reports that the session has expired, the client logs in
+// again and replays the request exactly once. Transient failures are retried
+// according to the client's retry policy.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
-	// Add session cookie to all requests
+	// Buffer the body so it can be sent again on replay or retry
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to buffer request body: %w", err)
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
+	reauthenticated := false
+	retries := 0
+	for {
+		session := c.currentSession()
+		setSessionCookie(req, session)
+
+		resp, sent, err := c.sendTraced(req)
+
+		if err == nil && isSessionExpired(resp) && !reauthenticated {
+			// Session expired: discard the response, log in again and replay
+			drainBody(resp)
+			if err := c.reauthenticate(req.Context(), session); err != nil {
+				return nil, fmt.Errorf("failed to re-authenticate after session expiry: %w", err)
+			}
+			reauthenticated = true
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if retries >= c.retry.maxRetries || !shouldRetry(req, resp, err, sent) {
+			return resp, err
+		}
+
+		wait := c.retry.backoff(retries, resp)
+		logRetry(req, resp, err, retries, wait)
+		if resp != nil {
+			drainBody(resp)
+		}
+		if err := waitForRetry(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		if err := rewindBody(req); err != nil {
+			return nil, err
+		}
+		retries++
+	}
+}
+
+// rewindBody resets a buffered request body before sending it again
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	req.Body = body
+	return nil
+}
+
+// drainBody discards and closes a response that will not be returned
+func drainBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// setSessionCookie replaces any session cookie already present on req
+func setSessionCookie(req *http.Request, session string) {
+	req.Header.Del("Cookie")
 	req.AddCookie(&http.Cookie{
-		Name:  "unetlab_session",
-		Value: c.session,
+		Name:  sessionCookieName,
+		Value: session,
 		Path:  "/api/",
 	})
+}
 
-	return c.httpClient.Do(req)
+// isSessionExpired reports whether EVE-NG rejected the request because the
+// session cookie is missing or has timed out (HTTP 412 or 401)
+func isSessionExpired(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusUnauthorized
 }
 
 // Get performs a GET request
 func (c *Client) Get(path string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", c.baseURL+path, http.NoBody)
+	return c.GetContext(context.Background(), path)
+}
+
+// GetContext performs a GET request that is aborted when ctx is done
+func (c *Client) GetContext(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, http.NoBody)
 	if err != nil {
 		return nil, err
 	}
@@ -173,26 +316,40 @@ func (c *Client) Get(path string) (*http.Response, error) {
 
 // Post performs a POST request
 func (c *Client) Post(path string, body interface{}) (*http.Response, error) {
-	var reqBody io.Reader
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
-		}
-		reqBody = bytes.NewBuffer(jsonBody)
-	}
+	return c.PostContext(context.Background(), path, body)
+}
+
+// PostContext performs a POST request that is aborted when ctx is done
+func (c *Client) PostContext(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	return c.doJSON(ctx, "POST", path, body)
+}
+
+// Put performs a PUT request
+func (c *Client) Put(path string, body interface{}) (*http.Response, error) {
+	return c.PutContext(context.Background(), path, body)
+}
+
+// PutContext performs a PUT request that is aborted when ctx is done
+func (c *Client) PutContext(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	return c.doJSON(ctx, "PUT", path, body)
+}
 
-	req, err := http.NewRequest("POST", c.baseURL+path, reqBody)
+// Delete performs a DELETE request
+func (c *Client) Delete(path string) (*http.Response, error) {
+	return c.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext performs a DELETE request that is aborted when ctx is done
+func (c *Client) DeleteContext(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.baseURL+path, http.NoBody)
 	if err != nil {
 		return nil, err
 	}
-
-	req.Header.Set("Content-Type", "application/json")
 	return c.Do(req)
 }
 
-// Put performs a PUT request
-func (c *Client) Put(path string, body interface{}) (*http.Response, error) {
+// doJSON sends body, if any, as a JSON document
+func (c *Client) doJSON(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -202,7 +359,7 @@ func (c *Client) Put(path string, body interface{}) (*http.Response, error) {
 		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequest("PUT", c.baseURL+path, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -211,16 +368,8 @@ func (c *Client) Put(path string, body interface{}) (*http.Response, error) {
 	return c.Do(req)
 }
 
-// Delete performs a DELETE request
-func (c *Client) Delete(path string) (*http.Response, error) {
-	req, err := http.NewRequest("DELETE", c.baseURL+path, http.NoBody)
-	if err != nil {
-		return nil, err
-	}
-	return c.Do(req)
-}
-
-// HandleResponse handles API responses and extracts data
+// HandleResponse handles API responses and extracts data.
+// Failed requests are reported as *APIError.
 func (c *Client) HandleResponse(resp *http.Response, result interface{}) error {
 	defer resp.Body.Close()
 
@@ -230,15 +379,11 @@ func (c *Client) HandleResponse(resp *http.Response, result interface{}) error {
 	}
 
 	if resp.StatusCode >= 400 {
-		var errorResp struct {
-			Code    int    `json:"code"`
-			Status  string `json:"status"`
-			Message string `json:"message"`
-		}
+		var errorResp Response[json.RawMessage]
 		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return fmt.Errorf("API error %d: %s", errorResp.Code, errorResp.Message)
+			return newAPIError(resp, body, errorResp.Code, errorResp.Message)
 		}
-		return fmt.Errorf("API error with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body, 0, "")
 	}
 
 	if result != nil {
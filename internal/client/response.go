@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Response is the envelope EVE-NG wraps around every API reply
+type Response[T any] struct {
+	Code    int    `json:"code"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Data    T      `json:"data"`
+}
+
+// APIError describes a request that EVE-NG rejected
+type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Code is the "code" field of the EVE-NG envelope, if any
+	Code int
+	// Message is the "message" field of the envelope, or the raw body
+	Message string
+	// Method and Path identify the failed request
+	Method string
+	Path   string
+}
+
+func (e *APIError) Error() string {
+	code := e.Code
+	if code == 0 {
+		code = e.StatusCode
+	}
+	if e.Method == "" {
+		return fmt.Sprintf("API error %d: %s", code, e.Message)
+	}
+	return fmt.Sprintf("API error %d: %s (%s %s)", code, e.Message, e.Method, e.Path)
+}
+
+// hasCode reports whether either the HTTP status or the EVE code matches
+func (e *APIError) hasCode(codes ...int) bool {
+	for _, code := range codes {
+		if e.StatusCode == code || e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err is an API error for a missing object,
+// e.g. a lab or node that was deleted outside of Terraform
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.hasCode(http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an API error caused by a missing,
+// expired or insufficiently privileged session
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.hasCode(http.StatusUnauthorized, http.StatusForbidden, http.StatusPreconditionFailed)
+}
+
+// IsConflict reports whether err is an API error for an object that already
+// exists or is in a conflicting state
+func IsConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.hasCode(http.StatusConflict)
+}
+
+// newAPIError builds an APIError from a response and its already read body
+func newAPIError(resp *http.Response, body []byte, code int, message string) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Code:       code,
+		Message:    message,
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = string(body)
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Path = resp.Request.URL.Path
+	}
+	return apiErr
+}
+
+// DecodeResponse reads an EVE-NG reply into a typed envelope. It returns an
+// *APIError when the HTTP status or the envelope code signals a failure.
+// It is a function rather than a Client method because Go methods cannot
+// take type parameters.
+func DecodeResponse[T any](resp *http.Response) (*Response[T], error) {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var result Response[T]
+	decodeErr := json.Unmarshal(body, &result)
+
+	if resp.StatusCode >= 400 {
+		if decodeErr != nil {
+			return nil, newAPIError(resp, body, 0, "")
+		}
+		return nil, newAPIError(resp, body, result.Code, result.Message)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", decodeErr)
+	}
+	if result.Code >= 400 {
+		return nil, newAPIError(resp, body, result.Code, result.Message)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Default retry settings used when the configuration leaves them unset
+const (
+	defaultRetryWaitMin = 1 * time.Second
+	defaultRetryWaitMax = 30 * time.Second
+)
+
+// transientMessages are fragments of EVE-NG error messages reported while a
+// lab is temporarily busy; such requests succeed once the lab is released
+var transientMessages = []string{
+	"is being saved",
+	"temporarily unavailable",
+}
+
+// retryPolicy controls how failed requests are retried
+type retryPolicy struct {
+	// maxRetries is the number of additional attempts after the first one
+	maxRetries int
+	// waitMin is the backoff before the first retry
+	waitMin time.Duration
+	// waitMax caps the backoff between attempts
+	waitMax time.Duration
+}
+
+// newRetryPolicy builds a policy from the client configuration, filling in
+// defaults for unset wait bounds
+func newRetryPolicy(config *Config) retryPolicy {
+	policy := retryPolicy{
+		maxRetries: config.RetryMax,
+		waitMin:    config.RetryWaitMin,
+		waitMax:    config.RetryWaitMax,
+	}
+	if policy.maxRetries < 0 {
+		policy.maxRetries = 0
+	}
+	if policy.waitMin <= 0 {
+		policy.waitMin = defaultRetryWaitMin
+	}
+	if policy.waitMax <= 0 {
+		policy.waitMax = defaultRetryWaitMax
+	}
+	if policy.waitMax < policy.waitMin {
+		policy.waitMax = policy.waitMin
+	}
+	return policy
+}
+
+// backoff returns the wait before retry number attempt (starting at 0): an
+// exponentially growing delay capped at waitMax, with jitter in its upper half
+func (p retryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp); ok {
+		if wait > p.waitMax {
+			return p.waitMax
+		}
+		return wait
+	}
+
+	wait := float64(p.waitMin) * math.Pow(2, float64(attempt))
+	if wait > float64(p.waitMax) {
+		wait = float64(p.waitMax)
+	}
+	half := int64(wait / 2)
+	if half <= 0 {
+		return time.Duration(wait)
+	}
+	// #nosec G404 -- jitter does not need a cryptographically secure source
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// retryAfter parses a Retry-After header expressed in seconds
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// actionSuffixes end the paths of PUT requests that act on a lab instead of
+// storing a value, such as moving or locking it; they are never repeated
+var actionSuffixes = []string{
+	"/move",
+	"/Lock",
+	"/Unlock",
+}
+
+// isIdempotent reports whether a request can be safely repeated after it may
+// already have been processed by the server
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	case http.MethodPut:
+		for _, suffix := range actionSuffixes {
+			if strings.HasSuffix(req.URL.Path, suffix) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldRetry classifies the outcome of one attempt. sent tells whether the
+// request was written to the connection, so that non-idempotent requests are
+// only repeated when the server provably never saw them.
+func shouldRetry(req *http.Request, resp *http.Response, err error, sent bool) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return !sent || isIdempotent(req)
+	}
+
+	// The server received the request; only idempotent requests may be repeated
+	if !isIdempotent(req) {
+		return false
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return true
+	case resp.StatusCode >= 400:
+		return hasTransientMessage(resp)
+	default:
+		return false
+	}
+}
+
+// hasTransientMessage inspects an error body for busy-lab messages, restoring
+// the body so the caller can still read it
+func hasTransientMessage(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	text := strings.ToLower(string(body))
+	for _, msg := range transientMessages {
+		if strings.Contains(text, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendTraced performs a single HTTP round trip and reports whether the
+// request headers were written to the connection
+func (c *Client) sendTraced(req *http.Request) (*http.Response, bool, error) {
+	var sent atomic.Bool
+	trace := &httptrace.ClientTrace{
+		WroteHeaders: func() { sent.Store(true) },
+	}
+	traced := req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	resp, err := c.httpClient.Do(traced)
+	return resp, sent.Load(), err
+}
+
+// waitForRetry sleeps for the backoff duration unless the request context
+// is cancelled first
+func waitForRetry(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// logRetry records why a request is being retried
+func logRetry(req *http.Request, resp *http.Response, err error, attempt int, wait time.Duration) {
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	} else {
+		reason = resp.Status
+	}
+	log.Printf("[WARN] %s %s failed (%s), retrying in %s (attempt %d)", req.Method, req.URL.Path, reason, wait, attempt+1)
+}
@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+// flakyLabServer fails a configurable number of requests per endpoint with a
+// server error before answering normally
+type flakyLabServer struct {
+	mu           sync.Mutex
+	failReads    int
+	failCreates  int
+	createCalls  int
+	readFailures int
+}
+
+func setupMockEVEWithFlakyLab(state *flakyLabServer) *httptest.Server {
+	mux := http.NewServeMux()
+
+	setupLoginEndpoint(mux)
+
+	mux.HandleFunc("/api/labs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != labHTTPMethodPOST {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		state.mu.Lock()
+		state.createCalls++
+		fail := state.createCalls <= state.failCreates
+		state.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"code":500,"status":"fail","message":"Internal error"}`)
+			return
+		}
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab created"}`)
+	})
+
+	mux.HandleFunc("/api/labs/test-lab.unl", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case labHTTPMethodGET:
+			state.mu.Lock()
+			fail := state.readFailures < state.failReads
+			if fail {
+				state.readFailures++
+			}
+			state.mu.Unlock()
+
+			if fail {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, `{"code":503,"status":"fail","message":"Service unavailable"}`)
+				return
+			}
+			fmt.Fprint(w, `{
+				"code": 200,
+				"status": "success",
+				"message": "Lab loaded",
+				"data": {
+					"author": "test",
+					"description": "test lab",
+					"body": "",
+					"filename": "test-lab.unl",
+					"id": "test-lab-id",
+					"name": "test-lab",
+					"version": "1",
+					"scripttimeout": 300,
+					"lock": false
+				}
+			}`)
+		case labHTTPMethodDELETE:
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab deleted"}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func retryTestConfig(serverURL string) string {
+	return fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+			insecure_skip_verify = true
+			retry_max = 3
+			retry_wait_min = "10ms"
+			retry_wait_max = "50ms"
+		}
+		resource "eve_lab" "test" {
+			path = "/"
+			name = "test-lab"
+			author = "test"
+			description = "test lab"
+			version = "1"
+		}
+	`, serverURL)
+}
+
+func TestClientRetriesTransientReadFailures(t *testing.T) {
+	state := &flakyLabServer{failReads: 2}
+	server := setupMockEVEWithFlakyLab(state)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: retryTestConfig(server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab.test", "file", "/test-lab.unl"),
+					func(_ *terraform.State) error {
+						state.mu.Lock()
+						defer state.mu.Unlock()
+						if state.readFailures != 2 {
+							return fmt.Errorf("expected 2 failed reads, got %d", state.readFailures)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestClientDoesNotRetryDeliveredCreate(t *testing.T) {
+	state := &flakyLabServer{failCreates: 1}
+	server := setupMockEVEWithFlakyLab(state)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      retryTestConfig(server.URL),
+				ExpectError: regexp.MustCompile("Internal error"),
+			},
+		},
+		CheckDestroy: func(_ *terraform.State) error {
+			state.mu.Lock()
+			defer state.mu.Unlock()
+			if state.createCalls != 1 {
+				return fmt.Errorf("expected lab creation to be attempted once, got %d", state.createCalls)
+			}
+			return nil
+		},
+	})
+}
+
+func TestClientDoesNotRetryLabActions(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+	mux.HandleFunc("/api/labs/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"code":503,"status":"fail","message":"Service unavailable"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := client.NewClient(&client.Config{
+		Endpoint:     server.URL,
+		Username:     "testuser",
+		Password:     "testpass",
+		Timeout:      5 * time.Second,
+		RetryMax:     2,
+		RetryWaitMin: 10 * time.Millisecond,
+		RetryWaitMax: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]int{
+		"/api/labs/test-lab.unl":        3,
+		"/api/labs/test-lab.unl/move":   1,
+		"/api/labs/test-lab.unl/Lock":   1,
+		"/api/labs/test-lab.unl/Unlock": 1,
+	} {
+		if resp, err := c.PutContext(context.Background(), strings.TrimPrefix(path, "/"), map[string]interface{}{}); err == nil {
+			resp.Body.Close()
+		}
+		mu.Lock()
+		got := calls[path]
+		mu.Unlock()
+		if got != want {
+			t.Errorf("expected PUT %s to be sent %d times, got %d", path, want, got)
+		}
+	}
+}
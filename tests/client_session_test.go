@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// expiringSessionServer issues a fresh session on every login and invalidates
+// it after a fixed number of authenticated requests
+type expiringSessionServer struct {
+	mu            sync.Mutex
+	logins        int
+	expired       int
+	validSession  string
+	remainingUses int
+	usesPerLogin  int
+}
+
+func (s *expiringSessionServer) login(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logins++
+	s.validSession = fmt.Sprintf("mock_session_%d", s.logins)
+	s.remainingUses = s.usesPerLogin
+
+	w.Header().Set("Content-Type", "application/json")
+	http.SetCookie(w, &http.Cookie{
+		Name:  "unetlab_session",
+		Value: s.validSession,
+		Path:  "/api/",
+	})
+	fmt.Fprint(w, `{"code":200,"status":"success","message":"User logged in (90013)."}`)
+}
+
+// authorize consumes one use of the session carried by r, replying with 412
+// when the session is unknown or already used up
+func (s *expiringSessionServer) authorize(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cookie, err := r.Cookie("unetlab_session")
+	if err == nil && cookie.Value == s.validSession && s.remainingUses > 0 {
+		s.remainingUses--
+		return true
+	}
+
+	s.expired++
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusPreconditionFailed)
+	fmt.Fprint(w, `{"code":412,"status":"unauthorized","message":"User is not authenticated or session timed out (90001)."}`)
+	return false
+}
+
+func setupMockEVEWithExpiringSession(usesPerLogin int) (*httptest.Server, *expiringSessionServer) {
+	state := &expiringSessionServer{usesPerLogin: usesPerLogin}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != labHTTPMethodPOST {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		state.login(w)
+	})
+
+	mux.HandleFunc("/api/labs", func(w http.ResponseWriter, r *http.Request) {
+		if !state.authorize(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != labHTTPMethodPOST {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// A replayed request must carry the original body
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload["name"] != "test-lab" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":400,"status":"fail","message":"Missing lab name"}`)
+			return
+		}
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab created"}`)
+	})
+
+	mux.HandleFunc("/api/labs/test-lab.unl", func(w http.ResponseWriter, r *http.Request) {
+		if !state.authorize(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case labHTTPMethodGET:
+			fmt.Fprint(w, `{
+				"code": 200,
+				"status": "success",
+				"message": "Lab loaded",
+				"data": {
+					"author": "test",
+					"description": "test lab",
+					"body": "",
+					"filename": "test-lab.unl",
+					"id": "test-lab-id",
+					"name": "test-lab",
+					"version": "1",
+					"scripttimeout": 300,
+					"lock": false
+				}
+			}`)
+		case labHTTPMethodDELETE:
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab deleted"}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	return httptest.NewServer(mux), state
+}
+
+func TestClientReauthenticatesOnExpiredSession(t *testing.T) {
+	// Every session is good for a single request, so each POST and GET after
+	// the first one in a provider instance hits an expired cookie
+	server, state := setupMockEVEWithExpiringSession(1)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "eve" {
+						endpoint = "%s"
+						username = "testuser"
+						password = "testpass"
+						insecure_skip_verify = true
+					}
+					resource "eve_lab" "test" {
+						path = "/"
+						name = "test-lab"
+						author = "test"
+						description = "test lab"
+						version = "1"
+					}
+				`, server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab.test", "file", "/test-lab.unl"),
+					resource.TestCheckResourceAttr("eve_lab.test", "description", "test lab"),
+					func(_ *terraform.State) error {
+						state.mu.Lock()
+						defer state.mu.Unlock()
+						if state.expired == 0 {
+							return fmt.Errorf("expected at least one request with an expired session")
+						}
+						if state.logins < 2 {
+							return fmt.Errorf("expected client to log in again, got %d logins", state.logins)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func setupMockEVEWithIcons(status int) *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+
+	mux.HandleFunc("/api/icons", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"code":%d,"status":"fail","message":"Unexpected error"}`, status)
+			return
+		}
+		fmt.Fprint(w, `{
+			"code": 200,
+			"status": "success",
+			"message": "Successfully listed node icons (60044).",
+			"data": {"Switch.png": "Switch", "Router.png": "Router"}
+		}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func iconsConfig(serverURL string) string {
+	return fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+		}
+		data "eve_icons" "all" {}
+	`, serverURL)
+}
+
+func TestEveIconsDataSource(t *testing.T) {
+	server := setupMockEVEWithIcons(http.StatusOK)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: iconsConfig(server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.eve_icons.all", "icons.#", "2"),
+					resource.TestCheckResourceAttr("data.eve_icons.all", "icons.0", "Router.png"),
+					resource.TestCheckResourceAttr("data.eve_icons.all", "icons.1", "Switch.png"),
+				),
+			},
+		},
+	})
+}
+
+func TestEveIconsDataSourceError(t *testing.T) {
+	server := setupMockEVEWithIcons(http.StatusBadRequest)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      iconsConfig(server.URL),
+				ExpectError: regexp.MustCompile("failed to list icons"),
+			},
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestEveLabDataSource(t *testing.T) {
+	lab := newTopologyLab()
+	r1, r2 := lab.addNode("r1"), lab.addNode("r2")
+	lan := lab.addNetwork("lan")
+	lab.rewire(r1, 0, lan)
+	lab.rewire(r2, 1, lan)
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "eve" {
+						endpoint = "%s"
+						username = "testuser"
+						password = "testpass"
+					}
+					data "eve_lab" "golden" {
+						file = "/test-lab.unl"
+					}
+				`, server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "node.#", "2"),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "node.0.name", "r1"),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "node.0.status", "stopped"),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "node_ids.r2", fmt.Sprint(r2)),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "network.#", "1"),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "network_ids.lan", fmt.Sprint(lan)),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "link.#", "2"),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "link.0.node_name", "r1"),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "link.0.interface", "e0"),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "link.1.interface", "e1"),
+					resource.TestCheckResourceAttr("data.eve_lab.golden", "link.1.network_name", "lan"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// setupMockEVEWithFolderTree serves /root.unl, /projects/old-lab.unl and
+// /projects/ci/ci-lab.unl, counting the reads of each lab in reads
+func setupMockEVEWithFolderTree(reads *labReads) *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+
+	folders := map[string]string{
+		"/api/folders": `{"folders":[{"name":"projects","path":"/projects"}],
+			"labs":[{"file":"root.unl","path":"/root.unl","mtime":"12 Oct 2025 15:05","umtime":1760274335}]}`,
+		"/api/folders/projects": `{"folders":[{"name":"..","path":"/"},{"name":"ci","path":"/projects/ci"}],
+			"labs":[{"file":"old-lab.unl","path":"/projects/old-lab.unl","mtime":"01 Jan 2024 00:00","umtime":1704067200}]}`,
+		"/api/folders/projects/ci": `{"folders":[{"name":"..","path":"/projects"}],
+			"labs":[{"file":"ci-lab.unl","path":"/projects/ci/ci-lab.unl","mtime":"12 Oct 2025 15:05","umtime":1760274335}]}`,
+	}
+	for path, data := range folders {
+		data := data
+		mux.HandleFunc(path, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"code":200,"status":"success","message":"Successfully listed path (60007).","data":%s}`, data)
+		})
+	}
+
+	labs := map[string]string{
+		"/api/labs/root.unl":               `{"name":"root","author":"admin","description":"","lock":0}`,
+		"/api/labs/projects/old-lab.unl":   `{"name":"old-lab","author":"alice","description":"Stale lab","lock":1}`,
+		"/api/labs/projects/ci/ci-lab.unl": `{"name":"ci-lab","author":"ci","description":"CI lab","lock":false}`,
+	}
+	for path, data := range labs {
+		path, data := path, data
+		mux.HandleFunc(path, func(w http.ResponseWriter, _ *http.Request) {
+			reads.add(path)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"code":200,"status":"success","message":"Lab has been loaded (60020).","data":%s}`, data)
+		})
+	}
+	return httptest.NewServer(mux)
+}
+
+// labReads counts the reads of each lab
+type labReads struct {
+	mu    sync.Mutex
+	count map[string]int
+}
+
+func (r *labReads) add(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.count == nil {
+		r.count = map[string]int{}
+	}
+	r.count[path]++
+}
+
+func (r *labReads) get(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count[path]
+}
+
+func TestEveLabsDataSource(t *testing.T) {
+	server := setupMockEVEWithFolderTree(&labReads{})
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "eve" {
+						endpoint = "%s"
+						username = "testuser"
+						password = "testpass"
+					}
+					data "eve_labs" "root" {}
+					data "eve_labs" "projects" {
+						path      = "/projects"
+						recursive = true
+					}
+					data "eve_labs" "old" {
+						recursive  = true
+						name_regex = "^old-"
+					}
+				`, server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.eve_labs.root", "labs.#", "1"),
+					resource.TestCheckResourceAttr("data.eve_labs.root", "labs.0.file", "/root.unl"),
+					resource.TestCheckResourceAttr("data.eve_labs.projects", "labs.#", "2"),
+					resource.TestCheckResourceAttr("data.eve_labs.projects", "labs.0.file", "/projects/ci/ci-lab.unl"),
+					resource.TestCheckResourceAttr("data.eve_labs.projects", "labs.0.folder", "/projects/ci"),
+					resource.TestCheckResourceAttr("data.eve_labs.old", "labs.#", "1"),
+					resource.TestCheckResourceAttr("data.eve_labs.old", "labs.0.name", "old-lab"),
+					resource.TestCheckResourceAttr("data.eve_labs.old", "labs.0.author", "alice"),
+					resource.TestCheckResourceAttr("data.eve_labs.old", "labs.0.locked", "true"),
+					resource.TestCheckResourceAttr("data.eve_labs.old", "labs.0.umtime", "1704067200"),
+				),
+			},
+		},
+	})
+}
+
+func TestEveLabsDataSourceFetchesMatchingLabsOnly(t *testing.T) {
+	reads := &labReads{}
+	server := setupMockEVEWithFolderTree(reads)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "eve" {
+						endpoint = "%s"
+						username = "testuser"
+						password = "testpass"
+					}
+					data "eve_labs" "ci" {
+						recursive  = true
+						name_regex = "^ci-"
+					}
+				`, server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.eve_labs.ci", "labs.#", "1"),
+					resource.TestCheckResourceAttr("data.eve_labs.ci", "labs.0.file", "/projects/ci/ci-lab.unl"),
+					func(_ *terraform.State) error {
+						for _, path := range []string{"/api/labs/root.unl", "/api/labs/projects/old-lab.unl"} {
+							if n := reads.get(path); n != 0 {
+								return fmt.Errorf("expected %s not to be read, got %d reads", path, n)
+							}
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func setupMockEVEWithNodeConfigs() *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"code": 200,
+			"status": "success",
+			"message": "Successfully listed nodes (60026).",
+			"data": {
+				"1": {"id": 1, "name": "r1", "type": "iol", "status": 0},
+				"2": {"id": 2, "name": "r2", "type": "iol", "status": 2}
+			}
+		}`)
+	})
+
+	for id, name := range map[int]string{1: "r1", 2: "r2"} {
+		id, name := id, name
+		mux.HandleFunc(fmt.Sprintf("/api/labs/test-lab.unl/configs/%d", id), func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{
+				"code": 200,
+				"status": "success",
+				"message": "Got config (60056).",
+				"data": {"id": %d, "name": %q, "data": "hostname %s\n"}
+			}`, id, name, name)
+		})
+	}
+
+	return httptest.NewServer(mux)
+}
+
+func TestEveNodeConfigsDataSource(t *testing.T) {
+	server := setupMockEVEWithNodeConfigs()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "eve" {
+						endpoint = "%s"
+						username = "testuser"
+						password = "testpass"
+					}
+					data "eve_node_configs" "all" {
+						lab_file = "/test-lab.unl"
+					}
+					data "eve_node_configs" "r2" {
+						lab_file = "/test-lab.unl"
+						node_ids = ["/test-lab.unl:node:2"]
+					}
+				`, server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.eve_node_configs.all", "configs.%", "2"),
+					resource.TestCheckResourceAttr("data.eve_node_configs.all", "configs.r1", "hostname r1\n"),
+					resource.TestCheckResourceAttr("data.eve_node_configs.r2", "configs.%", "1"),
+					resource.TestCheckResourceAttr("data.eve_node_configs.r2", "configs.r2", "hostname r2\n"),
+				),
+			},
+		},
+	})
+}
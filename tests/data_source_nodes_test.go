@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestEveNodesAndNetworksDataSources(t *testing.T) {
+	lab := newTopologyLab()
+	lab.addNode("r1")
+	r2 := lab.addNode("r2")
+	lab.addNode("sw1")
+	lab.nodes[r2]["status"] = 2
+	lab.addNetwork("lan")
+	mgmt := lab.addNetwork("mgmt")
+	lab.networks[mgmt]["type"] = "pnet0"
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "eve" {
+						endpoint = "%s"
+						username = "testuser"
+						password = "testpass"
+					}
+					data "eve_nodes" "routers" {
+						lab_file   = "/test-lab.unl"
+						name_regex = "^r"
+					}
+					data "eve_nodes" "running" {
+						lab_file = "/test-lab.unl"
+						template = "linux"
+						status   = "started"
+					}
+					data "eve_networks" "cloud" {
+						lab_file = "/test-lab.unl"
+						type     = "pnet0"
+					}
+				`, server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.eve_nodes.routers", "nodes.#", "2"),
+					resource.TestCheckResourceAttr("data.eve_nodes.routers", "nodes.1.name", "r2"),
+					resource.TestCheckResourceAttr("data.eve_nodes.routers", "nodes.1.ethernet", "2"),
+					resource.TestCheckResourceAttr("data.eve_nodes.running", "nodes.#", "1"),
+					resource.TestCheckResourceAttr("data.eve_nodes.running", "nodes.0.id", fmt.Sprint(r2)),
+					resource.TestCheckResourceAttr("data.eve_nodes.running", "nodes.0.current_state", "started"),
+					resource.TestCheckResourceAttr("data.eve_networks.cloud", "networks.#", "1"),
+					resource.TestCheckResourceAttr("data.eve_networks.cloud", "networks.0.name", "mgmt"),
+					resource.TestCheckResourceAttr("data.eve_networks.cloud", "networks.0.visibility", "1"),
+				),
+			},
+		},
+	})
+}
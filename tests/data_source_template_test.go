@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func setupMockEVEWithTemplate() *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+
+	mux.HandleFunc("/api/list/templates/vyos", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"Template loaded","data":{
+			"type": "qemu",
+			"description": "VyOS",
+			"options": {
+				"image": {"name": "Image", "type": "list", "list": {"vyos-1.10": "vyos-1.10", "vyos-1.4": "vyos-1.4", "vyos-1.9": "vyos-1.9"}, "value": "vyos-1.4"},
+				"icon": {"name": "Icon", "type": "list", "list": {"Router.png": "Router"}, "value": "Router.png"},
+				"cpu": {"name": "CPU", "type": "input", "value": 1},
+				"ram": {"name": "RAM (MB)", "type": "input", "value": "2048"},
+				"ethernet": {"name": "Ethernets", "type": "input", "value": "4"},
+				"qemu_nic": {"name": "QEMU NIC", "type": "list", "list": {"virtio-net-pci": "virtio-net-pci", "e1000": "e1000"}, "value": "virtio-net-pci"},
+				"console": {"name": "Console", "type": "list", "list": ["telnet", "vnc"], "value": "telnet"}
+			}
+		}}`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestEveTemplateDataSource(t *testing.T) {
+	server := setupMockEVEWithTemplate()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "eve" {
+						endpoint = "%s"
+						username = "testuser"
+						password = "testpass"
+					}
+					data "eve_template" "vyos" {
+						name = "vyos"
+					}
+				`, server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "type", "qemu"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "images.#", "3"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "images.0", "vyos-1.4"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "default_image", "vyos-1.4"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "latest_image", "vyos-1.10"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "nic_models.#", "2"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "console_types.1", "vnc"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "cpu", "1"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "ram", "2048"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "ethernet", "4"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "serial", "0"),
+					resource.TestCheckResourceAttr("data.eve_template.vyos", "options.#", "7"),
+				),
+			},
+		},
+	})
+}
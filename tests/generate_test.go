@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	eveng "github.com/nawada0615/terraform-provider-eve-ng/eve-ng"
+	"github.com/nawada0615/terraform-provider-eve-ng/internal/client"
+)
+
+func TestGenerateConfig(t *testing.T) {
+	lab := newTopologyLab()
+	r1, r2 := lab.addNode("r1"), lab.addNode("core-sw 1")
+	lab.nodes[r2]["status"] = 2
+	mgmt := lab.addNetwork("mgmt")
+	lab.addNetwork("${unused}")
+	lab.rewire(r1, 0, mgmt)
+	lab.rewire(r2, 1, mgmt)
+	link := lab.addNetwork("link_1_1_2_0")
+	lab.networks[link]["visibility"] = "0"
+	lab.rewire(r1, 1, link)
+	lab.rewire(r2, 0, link)
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	c, err := client.NewClient(&client.Config{Endpoint: server.URL, Username: "testuser", Password: "testpass", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := eveng.GenerateConfig(context.Background(), c, "/test-lab.unl", &out); err != nil {
+		t.Fatal(err)
+	}
+	config := out.String()
+
+	if _, diags := hclsyntax.ParseConfig([]byte(config), "generated.tf", hcl.InitialPos); diags.HasErrors() {
+		t.Fatalf("generated configuration does not parse: %s\n%s", diags, config)
+	}
+	for _, want := range []string{
+		`resource "eve_lab" "test-lab" {`,
+		`resource "eve_network" "mgmt" {`,
+		`name     = "$${unused}"`,
+		`resource "eve_node" "core-sw_1" {`,
+		`desired_state = "started"`,
+		`resource "eve_interface_attachment" "r1_e0" {`,
+		`node_id        = tonumber(split(":node:", eve_node.r1.id)[1])`,
+		`target         = "network:${tonumber(split(":network:", eve_network.mgmt.id)[1])}"`,
+		fmt.Sprintf("to = eve_interface_attachment.core-sw_1_e1\n  id = \"/test-lab.unl:ifattach:%d:1\"", r2),
+		fmt.Sprintf("to = eve_node.r1\n  id = \"/test-lab.unl:node:%d\"", r1),
+		`resource "eve_link" "r1_e1_core-sw_1_e0" {`,
+		`b_node_id    = tonumber(split(":node:", eve_node.core-sw_1.id)[1])`,
+		fmt.Sprintf("to = eve_link.r1_e1_core-sw_1_e0\n  id = \"/test-lab.unl:link:%d\"", link),
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("expected the generated configuration to contain %q:\n%s", want, config)
+		}
+	}
+	for _, unwanted := range []string{`"link_1_1_2_0" {`, "eve_interface_attachment.r1_e1"} {
+		if strings.Contains(config, unwanted) {
+			t.Errorf("expected the link network to be generated as an eve_link only:\n%s", config)
+		}
+	}
+}
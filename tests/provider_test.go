@@ -32,7 +32,7 @@ func TestProviderSchema(t *testing.T) {
 	}
 
 	// Test optional fields
-	optionalFields := []string{"insecure_skip_verify", "timeout"}
+	optionalFields := []string{"insecure_skip_verify", "timeout", "retry_max", "retry_wait_min", "retry_wait_max"}
 	for _, field := range optionalFields {
 		if provider.Schema[field] == nil {
 			t.Errorf("Optional field %s is missing from provider schema", field)
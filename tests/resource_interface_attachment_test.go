@@ -1,12 +1,18 @@
 package tests
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 const (
@@ -183,11 +189,13 @@ func setupInterfaceEndpoints(mux *http.ServeMux) {
 				"status": "success",
 				"message": "Interfaces listed",
 				"data": {
-					"0": {
-						"name": "eth0",
-						"type": "ethernet",
-						"target": "network:1"
-					}
+					"ethernet": [
+						{"name": "e0", "network_id": 1},
+						{"name": "e1", "network_id": 0}
+					],
+					"serial": [],
+					"id": 1,
+					"sort": "qemu"
 				}
 			}`)
 		} else if r.Method == interfaceHTTPMethodPOST {
@@ -202,6 +210,53 @@ func setupInterfaceEndpoints(mux *http.ServeMux) {
 	})
 }
 
+func interfaceAttachmentConfig(serverURL string) string {
+	return fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+			insecure_skip_verify = true
+		}
+		resource "eve_lab" "test" {
+			path = "/"
+			name = "test-lab"
+			author = "test"
+			description = "test lab"
+			version = "1"
+		}
+		resource "eve_network" "test" {
+			lab_file = eve_lab.test.file
+			name = "test-net"
+			type = "bridge"
+			icon = "cloud.png"
+			top = 200
+			left = 200
+			visibility = "1"
+		}
+		resource "eve_node" "test" {
+			lab_file = eve_lab.test.file
+			name = "test-node"
+			type = "qemu"
+			template = "linux"
+			image = "linux-ubuntu-22.04"
+			icon = "Router-2D-Gen-White-S.svg"
+			top = 100
+			left = 100
+			cpu = 1
+			ram = 1024
+			ethernet = 4
+			desired_state = "stopped"
+		}
+		resource "eve_interface_attachment" "test" {
+			lab_file        = eve_lab.test.file
+			node_id         = tonumber(split(":node:", eve_node.test.id)[1])
+			interface_index = 0
+			target          = "network:${tonumber(split(":network:", eve_network.test.id)[1])}"
+		}
+	`, serverURL)
+}
+
 func TestEveInterfaceAttachmentCreate(t *testing.T) {
 	server := setupMockEVEForInterfaceAttachment()
 	defer server.Close()
@@ -210,50 +265,7 @@ func TestEveInterfaceAttachmentCreate(t *testing.T) {
 		ProviderFactories: getProviderFactories(),
 		Steps: []resource.TestStep{
 			{
-				Config: fmt.Sprintf(`
-					provider "eve" {
-						endpoint = "%s"
-						username = "testuser"
-						password = "testpass"
-						insecure_skip_verify = true
-					}
-					resource "eve_lab" "test" {
-						path = "/"
-						name = "test-lab"
-						author = "test"
-						description = "test lab"
-						version = "1"
-					}
-					resource "eve_network" "test" {
-						lab_file = eve_lab.test.file
-						name = "test-net"
-						type = "bridge"
-						icon = "cloud.png"
-						top = 200
-						left = 200
-						visibility = "1"
-					}
-					resource "eve_node" "test" {
-						lab_file = eve_lab.test.file
-						name = "test-node"
-						type = "qemu"
-						template = "linux"
-						image = "linux-ubuntu-22.04"
-						icon = "Router-2D-Gen-White-S.svg"
-						top = 100
-						left = 100
-						cpu = 1
-						ram = 1024
-						ethernet = 4
-						desired_state = "stopped"
-					}
-					resource "eve_interface_attachment" "test" {
-						lab_file        = eve_lab.test.file
-						node_id         = tonumber(split(":node:", eve_node.test.id)[1])
-						interface_index = 0
-						target          = "network:${tonumber(split(":network:", eve_network.test.id)[1])}"
-					}
-				`, server.URL),
+				Config: interfaceAttachmentConfig(server.URL),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("eve_interface_attachment.test", "lab_file", "/test-lab.unl"),
 					resource.TestCheckResourceAttr("eve_interface_attachment.test", "node_id", "1"),
@@ -264,3 +276,354 @@ func TestEveInterfaceAttachmentCreate(t *testing.T) {
 		},
 	})
 }
+
+// rewirableInterfaces tracks the network each ethernet interface of node 1 is
+// connected to, so tests can rewire it as if it was changed in the web UI
+type rewirableInterfaces struct {
+	mu       sync.Mutex
+	networks map[string]int
+}
+
+func (s *rewirableInterfaces) setNetwork(ifIndex string, networkID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.networks[ifIndex] = networkID
+}
+
+func setupMockEVEWithRewirableInterfaces(state *rewirableInterfaces) *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+	setupLabEndpoints(mux)
+	setupNetworkEndpoints(mux)
+	setupNodeEndpoints(mux)
+
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes/1/interfaces", func(w http.ResponseWriter, r *http.Request) {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case interfaceHTTPMethodGET:
+			ethernet := make([]map[string]interface{}, 4)
+			for i := range ethernet {
+				ethernet[i] = map[string]interface{}{
+					"name":       fmt.Sprintf("e%d", i),
+					"network_id": state.networks[strconv.Itoa(i)],
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code":    200,
+				"status":  "success",
+				"message": "Interfaces listed",
+				"data": map[string]interface{}{
+					"ethernet": ethernet,
+					"serial":   []interface{}{},
+					"id":       1,
+					"sort":     "qemu",
+				},
+			})
+		case interfaceHTTPMethodPUT:
+			var payload map[string]int
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			for ifIndex, networkID := range payload {
+				state.networks[ifIndex] = networkID
+			}
+			fmt.Fprint(w, `{"code":201,"status":"success","message":"Lab has been saved (60023)."}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestEveInterfaceAttachmentDetectsRewiring(t *testing.T) {
+	state := &rewirableInterfaces{networks: map[string]int{}}
+	server := setupMockEVEWithRewirableInterfaces(state)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: interfaceAttachmentConfig(server.URL),
+				Check:  resource.TestCheckResourceAttr("eve_interface_attachment.test", "target", "network:1"),
+			},
+			{
+				PreConfig:          func() { state.setNetwork("0", 2) },
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				Check:              resource.TestCheckResourceAttr("eve_interface_attachment.test", "target", "network:2"),
+			},
+			{
+				// Applying the configuration again restores the original wiring
+				Config: interfaceAttachmentConfig(server.URL),
+				Check:  resource.TestCheckResourceAttr("eve_interface_attachment.test", "target", "network:1"),
+			},
+		},
+	})
+}
+
+func TestEveInterfaceAttachmentRemovedWhenDetached(t *testing.T) {
+	state := &rewirableInterfaces{networks: map[string]int{}}
+	server := setupMockEVEWithRewirableInterfaces(state)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: interfaceAttachmentConfig(server.URL),
+			},
+			{
+				PreConfig:          func() { state.setNetwork("0", 0) },
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				Check: func(s *terraform.State) error {
+					if _, ok := s.RootModule().Resources["eve_interface_attachment.test"]; ok {
+						return fmt.Errorf("expected detached interface attachment to be removed from state")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// serialLab serves IOL nodes 1 and 2 with serial interfaces s1/0 (16) and
+// s1/1 (17), and node 3 without serial interfaces. links records the remote
+// each serial interface points to as "<node>:<if>".
+type serialLab struct {
+	mu    sync.Mutex
+	links map[int]map[int]string
+}
+
+func (l *serialLab) link(nodeID, ifIndex int) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.links[nodeID][ifIndex]
+}
+
+func (l *serialLab) setLink(nodeID, ifIndex int, remote string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.links[nodeID][ifIndex] = remote
+}
+
+func (l *serialLab) serialInterfaces(nodeID int) map[string]interface{} {
+	serial := map[string]interface{}{}
+	for idx, name := range map[int]string{16: "s1/0", 17: "s1/1"} {
+		entry := map[string]interface{}{"name": name, "remote_id": 0, "remote_if": 0}
+		var remoteID, remoteIf int
+		if _, err := fmt.Sscanf(l.links[nodeID][idx], "%d:%d", &remoteID, &remoteIf); err == nil {
+			entry["remote_id"], entry["remote_if"] = remoteID, remoteIf
+		}
+		serial[strconv.Itoa(idx)] = entry
+	}
+	return serial
+}
+
+func (l *serialLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/labs/test-lab.unl/nodes/"), "/")
+	nodeID, _ := strconv.Atoi(parts[0])
+	if nodeID < 1 || nodeID > 3 {
+		http.NotFound(w, r)
+		return
+	}
+	serialCount := 1
+	if nodeID == 3 {
+		serialCount = 0
+	}
+
+	var data interface{}
+	switch {
+	case len(parts) == 1:
+		data = map[string]interface{}{"id": nodeID, "name": fmt.Sprintf("r%d", nodeID), "type": "iol", "ethernet": 1, "serial": serialCount}
+	case r.Method == interfaceHTTPMethodPUT:
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		for idx, remote := range payload {
+			i, _ := strconv.Atoi(idx)
+			l.links[nodeID][i] = fmt.Sprint(remote)
+		}
+	default:
+		serial := map[string]interface{}{}
+		if serialCount > 0 {
+			serial = l.serialInterfaces(nodeID)
+		}
+		data = map[string]interface{}{
+			"ethernet": map[string]interface{}{"0": map[string]interface{}{"name": "e0/0", "network_id": 0}},
+			"serial":   serial,
+			"id":       nodeID,
+			"sort":     "iol",
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "status": "success", "message": "ok", "data": data})
+}
+
+func setupMockEVEWithSerialLab(lab *serialLab) *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+	mux.Handle("/api/labs/test-lab.unl/nodes/", lab)
+	return httptest.NewServer(mux)
+}
+
+func newSerialLab() *serialLab {
+	return &serialLab{links: map[int]map[int]string{1: {}, 2: {}, 3: {}}}
+}
+
+func serialAttachmentConfig(serverURL, link string) string {
+	return fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+		}
+		resource "eve_interface_attachment" "test" {
+			lab_file        = "/test-lab.unl"
+			node_id         = 1
+			interface_index = 16
+			%s
+		}
+	`, serverURL, link)
+}
+
+func TestEveInterfaceAttachmentSerialTarget(t *testing.T) {
+	lab := newSerialLab()
+	server := setupMockEVEWithSerialLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: serialAttachmentConfig(server.URL, `target = "node:2:17"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_interface_attachment.test", "target", "node:2:17"),
+					resource.TestCheckResourceAttr("eve_interface_attachment.test", "interface_name", "s1/0"),
+					resource.TestCheckResourceAttr("eve_interface_attachment.test", "remote_node_id", "2"),
+					resource.TestCheckResourceAttr("eve_interface_attachment.test", "remote_interface", "s1/1"),
+				),
+			},
+			{
+				ResourceName:      "eve_interface_attachment.test",
+				ImportState:       true,
+				ImportStateId:     "/test-lab.unl:ifattach:1:16",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestEveInterfaceAttachmentSerialRemoteIsSymmetric(t *testing.T) {
+	lab := newSerialLab()
+	server := setupMockEVEWithSerialLab(lab)
+	defer server.Close()
+
+	remote := `
+		remote_node_id   = 2
+		remote_interface = "s1/0"
+	`
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		CheckDestroy: func(_ *terraform.State) error {
+			if lab.link(1, 16) != "0" || lab.link(2, 16) != "0" {
+				return fmt.Errorf("expected both ends to be disconnected, got %q and %q", lab.link(1, 16), lab.link(2, 16))
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: serialAttachmentConfig(server.URL, remote),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_interface_attachment.test", "target", "node:2:16"),
+					func(_ *terraform.State) error {
+						if got := lab.link(2, 16); got != "1:16" {
+							return fmt.Errorf("expected node 2 s1/0 linked back to 1:16, got %q", got)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				PreConfig:          func() { lab.setLink(1, 16, "2:17") },
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				Check:              resource.TestCheckResourceAttr("eve_interface_attachment.test", "remote_interface", "s1/1"),
+			},
+			{
+				// Applying the configuration again restores the link
+				Config: serialAttachmentConfig(server.URL, remote),
+				Check:  resource.TestCheckResourceAttr("eve_interface_attachment.test", "remote_interface", "s1/0"),
+			},
+		},
+	})
+}
+
+func TestEveInterfaceAttachmentSerialRequiresSerialNodes(t *testing.T) {
+	lab := newSerialLab()
+	server := setupMockEVEWithSerialLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      serialAttachmentConfig(server.URL, `target = "node:3"`),
+				ExpectError: regexp.MustCompile(`node 3 has no serial interfaces`),
+			},
+		},
+	})
+}
+
+func namedInterfaceAttachmentConfig(serverURL, name string) string {
+	return strings.Replace(interfaceAttachmentConfig(serverURL), "interface_index = 0", fmt.Sprintf("interface_name  = %q", name), 1)
+}
+
+func TestEveInterfaceAttachmentByName(t *testing.T) {
+	state := &rewirableInterfaces{networks: map[string]int{}}
+	server := setupMockEVEWithRewirableInterfaces(state)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: namedInterfaceAttachmentConfig(server.URL, "e2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_interface_attachment.test", "interface_index", "2"),
+					resource.TestCheckResourceAttr("eve_interface_attachment.test", "interface_name", "e2"),
+					resource.TestCheckResourceAttr("eve_interface_attachment.test", "id", "/test-lab.unl:ifattach:1:2"),
+					func(_ *terraform.State) error {
+						state.mu.Lock()
+						defer state.mu.Unlock()
+						if state.networks["2"] != 1 {
+							return fmt.Errorf("expected interface 2 on network 1, got %v", state.networks)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestEveInterfaceAttachmentUnknownName(t *testing.T) {
+	state := &rewirableInterfaces{networks: map[string]int{}}
+	server := setupMockEVEWithRewirableInterfaces(state)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      namedInterfaceAttachmentConfig(server.URL, "Gi0/1"),
+				ExpectError: regexp.MustCompile(`no interface "Gi0/1" \(valid names: e0, e1, e2, e3\)`),
+			},
+		},
+	})
+}
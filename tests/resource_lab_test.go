@@ -1,12 +1,17 @@
 package tests
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 const (
@@ -136,3 +141,669 @@ func TestEveLabImport(t *testing.T) {
 		},
 	})
 }
+
+func TestEveLabImportPlansNoChanges(t *testing.T) {
+	lab := &movableLab{file: "/labs/test-lab.unl", description: "imported lab"}
+	server := setupMockEVEWithMovableLab(lab)
+	defer server.Close()
+
+	config := fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+		}
+		resource "eve_lab" "test" {
+			path = "/labs"
+			name = "test-lab"
+			description = "imported lab"
+		}
+	`, server.URL)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:             config,
+				ResourceName:       "eve_lab.test",
+				ImportState:        true,
+				ImportStateId:      "/labs/test-lab.unl",
+				ImportStatePersist: true,
+			},
+			{
+				// The imported lab neither moves nor changes
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// deletableLab is a lab that can be deleted behind Terraform's back; the
+// next POST recreates it
+type deletableLab struct {
+	mu      sync.Mutex
+	deleted bool
+	creates int
+}
+
+func (l *deletableLab) setDeleted() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.deleted = true
+}
+
+func setupMockEVEWithDeletableLab(lab *deletableLab) *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+
+	mux.HandleFunc("/api/labs", func(w http.ResponseWriter, r *http.Request) {
+		lab.mu.Lock()
+		defer lab.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		lab.deleted = false
+		lab.creates++
+		fmt.Fprint(w, `{"code":201,"status":"success","message":"Lab has been saved (60023)."}`)
+	})
+
+	mux.HandleFunc("/api/labs/test-lab.unl", func(w http.ResponseWriter, r *http.Request) {
+		lab.mu.Lock()
+		defer lab.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if lab.deleted {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code":404,"status":"fail","message":"Lab does not exist (60038)."}`)
+			return
+		}
+		if r.Method == labHTTPMethodDELETE {
+			lab.deleted = true
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab deleted"}`)
+			return
+		}
+		fmt.Fprint(w, `{
+			"code": 200,
+			"status": "success",
+			"message": "Lab loaded",
+			"data": {
+				"author": "test",
+				"description": "test lab",
+				"body": "",
+				"filename": "test-lab.unl",
+				"id": "test-lab-id",
+				"name": "test-lab",
+				"version": "1",
+				"scripttimeout": 300,
+				"lock": false
+			}
+		}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestEveLabDeletedOutOfBand(t *testing.T) {
+	lab := &deletableLab{}
+	server := setupMockEVEWithDeletableLab(lab)
+	defer server.Close()
+
+	config := createTestConfig(server.URL, "")
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				// The lab disappears from the server: refresh must drop it
+				// from state so that the apply creates it again
+				PreConfig: lab.setDeleted,
+				Config:    config,
+				Check: func(_ *terraform.State) error {
+					lab.mu.Lock()
+					defer lab.mu.Unlock()
+					if lab.creates != 2 {
+						return fmt.Errorf("expected the lab to be recreated, got %d creates", lab.creates)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// setupMockEVEWithHangingLabRead serves a lab whose read never answers until
+// the client gives up on the request
+func setupMockEVEWithHangingLabRead() *httptest.Server {
+	mux := http.NewServeMux()
+
+	setupLoginEndpoint(mux)
+
+	mux.HandleFunc("/api/labs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"code":201,"status":"success","message":"Lab has been saved (60023)."}`)
+	})
+
+	mux.HandleFunc("/api/labs/test-lab.unl", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == labHTTPMethodGET {
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab deleted"}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestEveLabCreateTimeout(t *testing.T) {
+	server := setupMockEVEWithHangingLabRead()
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "eve" {
+						endpoint = "%s"
+						username = "testuser"
+						password = "testpass"
+						timeout  = "5m"
+					}
+					resource "eve_lab" "test" {
+						path = "/"
+						name = "test-lab"
+						timeouts {
+							create = "1s"
+						}
+					}
+				`, server.URL),
+				ExpectError: regexp.MustCompile("context deadline exceeded"),
+			},
+		},
+	})
+}
+
+// editableLab keeps the lab metadata so that PUT requests are reflected in
+// subsequent reads; like EVE-NG, it rejects edits while locked
+type editableLab struct {
+	mu          sync.Mutex
+	creates     int
+	description string
+	locked      bool
+}
+
+func setupMockEVEWithEditableLab(lab *editableLab) *httptest.Server {
+	mux := http.NewServeMux()
+
+	setupLoginEndpoint(mux)
+
+	mux.HandleFunc("/api/labs", func(w http.ResponseWriter, r *http.Request) {
+		lab.mu.Lock()
+		defer lab.mu.Unlock()
+
+		var payload struct {
+			Description string `json:"description"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		lab.creates++
+		lab.description = payload.Description
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"code":201,"status":"success","message":"Lab has been saved (60023)."}`)
+	})
+
+	mux.HandleFunc("/api/labs/test-lab.unl", func(w http.ResponseWriter, r *http.Request) {
+		lab.mu.Lock()
+		defer lab.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case labHTTPMethodGET:
+			fmt.Fprintf(w, `{
+				"code": 200,
+				"status": "success",
+				"message": "Lab loaded",
+				"data": {
+					"author": "test",
+					"description": %q,
+					"body": "",
+					"filename": "test-lab.unl",
+					"id": "test-lab-id",
+					"name": "test-lab",
+					"version": "1",
+					"scripttimeout": 300,
+					"lock": %t
+				}
+			}`, lab.description, lab.locked)
+		case "PUT":
+			if lab.locked {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"code":400,"status":"fail","message":"Lab is locked (60061)."}`)
+				return
+			}
+			var payload map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			if v, ok := payload["description"].(string); ok {
+				lab.description = v
+			}
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab has been saved (60023)."}`)
+		default:
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab deleted"}`)
+		}
+	})
+
+	mux.HandleFunc("/api/labs/test-lab.unl/", func(w http.ResponseWriter, r *http.Request) {
+		lab.mu.Lock()
+		defer lab.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch strings.TrimPrefix(r.URL.Path, "/api/labs/test-lab.unl/") {
+		case "Lock":
+			lab.locked = true
+		case "Unlock":
+			lab.locked = false
+		}
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab has been saved (60023)."}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func labConfigWithDescription(serverURL, description string) string {
+	return fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+		}
+		resource "eve_lab" "test" {
+			path = "/"
+			name = "test-lab"
+			author = "test"
+			description = %q
+			version = "1"
+		}
+	`, serverURL, description)
+}
+
+func TestEveLabUpdateInPlace(t *testing.T) {
+	lab := &editableLab{}
+	server := setupMockEVEWithEditableLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: labConfigWithDescription(server.URL, "test lab"),
+				Check:  resource.TestCheckResourceAttr("eve_lab.test", "description", "test lab"),
+			},
+			{
+				Config: labConfigWithDescription(server.URL, "updated description"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab.test", "description", "updated description"),
+					func(_ *terraform.State) error {
+						lab.mu.Lock()
+						defer lab.mu.Unlock()
+						if lab.creates != 1 {
+							return fmt.Errorf("expected the lab to be updated in place, got %d creates", lab.creates)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func lockedLabConfig(serverURL, description string, lock bool) string {
+	return fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+		}
+		resource "eve_lab" "test" {
+			path = "/"
+			name = "test-lab"
+			author = "test"
+			description = %q
+			lock = %t
+		}
+	`, serverURL, description, lock)
+}
+
+func TestEveLabUpdateLockedLab(t *testing.T) {
+	lab := &editableLab{}
+	server := setupMockEVEWithEditableLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: lockedLabConfig(server.URL, "test lab", true),
+				Check:  resource.TestCheckResourceAttr("eve_lab.test", "lock", "true"),
+			},
+			{
+				// The lab is unlocked before the edit
+				Config: lockedLabConfig(server.URL, "unlocked", false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab.test", "description", "unlocked"),
+					resource.TestCheckResourceAttr("eve_lab.test", "lock", "false"),
+				),
+			},
+			{
+				// and locked after it
+				Config: lockedLabConfig(server.URL, "locked again", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab.test", "description", "locked again"),
+					resource.TestCheckResourceAttr("eve_lab.test", "lock", "true"),
+				),
+			},
+		},
+	})
+}
+
+// movableLab is a lab holding a single node that can be renamed through
+// the move API; other, when set or created, is another lab served alike.
+// Like EVE-NG, it rejects edits and moves while locked
+type movableLab struct {
+	mu          sync.Mutex
+	file        string
+	other       string
+	description string
+	locked      bool
+	labCreates  int
+	nodeCreates int
+	moves       int
+}
+
+func (l *movableLab) serveLab(w http.ResponseWriter, r *http.Request, file, rest string) {
+	switch {
+	case l.locked && r.Method == interfaceHTTPMethodPUT && (rest == "" || rest == "/move"):
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code":400,"status":"fail","message":"Lab is locked (60061)."}`)
+	case rest == "/Lock" || rest == "/Unlock":
+		l.locked = rest == "/Lock"
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab has been saved (60023)."}`)
+	case rest == "" && r.Method == interfaceHTTPMethodPUT:
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if v, ok := payload["description"].(string); ok {
+			l.description = v
+		}
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab has been saved (60023)."}`)
+	case rest == "/move" && r.Method == interfaceHTTPMethodPUT:
+		var payload struct {
+			Path string `json:"path"`
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		l.file = payload.Path + payload.Name + ".unl"
+		l.moves++
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"Lab moved"}`)
+	case rest == "/nodes" && r.Method == labHTTPMethodPOST:
+		l.nodeCreates++
+		fmt.Fprint(w, `{"code":201,"status":"success","message":"Node has been saved (60025).","data":{"id":1}}`)
+	case rest == "/nodes/1" && r.Method == labHTTPMethodGET:
+		fmt.Fprint(w, `{
+			"code": 200,
+			"status": "success",
+			"message": "Node retrieved",
+			"data": {"name": "test-node", "type": "qemu", "template": "linux", "status": 0}
+		}`)
+	case rest == "":
+		name := strings.TrimSuffix(strings.TrimPrefix(file, "/"), ".unl")
+		fmt.Fprintf(w, `{
+			"code": 200,
+			"status": "success",
+			"message": "Lab loaded",
+			"data": {"filename": %q, "name": %q, "description": %q, "version": "1", "scripttimeout": 300, "lock": %t}
+		}`, strings.TrimPrefix(file, "/"), name, l.description, l.locked)
+	default:
+		fmt.Fprint(w, `{"code":200,"status":"success","message":"OK"}`)
+	}
+}
+
+func setupMockEVEWithMovableLab(lab *movableLab) *httptest.Server {
+	mux := http.NewServeMux()
+
+	setupLoginEndpoint(mux)
+
+	mux.HandleFunc("/api/labs", func(w http.ResponseWriter, r *http.Request) {
+		lab.mu.Lock()
+		defer lab.mu.Unlock()
+
+		var payload struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		// The first lab created is test-lab, any later one the other lab
+		if lab.file == "" {
+			lab.file = "/test-lab.unl"
+			lab.description = payload.Description
+		} else {
+			lab.other = "/" + payload.Name + ".unl"
+		}
+		lab.labCreates++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"code":201,"status":"success","message":"Lab has been saved (60023)."}`)
+	})
+
+	mux.HandleFunc("/api/labs/", func(w http.ResponseWriter, r *http.Request) {
+		lab.mu.Lock()
+		defer lab.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/api/labs")
+		for _, file := range []string{lab.file, lab.other} {
+			if file != "" && strings.HasPrefix(path, file) {
+				lab.serveLab(w, r, file, strings.TrimPrefix(path, file))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code":404,"status":"fail","message":"Lab does not exist (60038)."}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func labConfigWithName(serverURL, name string) string {
+	return fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+		}
+		resource "eve_lab" "test" {
+			path = "/"
+			name = %q
+		}
+		resource "eve_node" "test" {
+			lab_file = eve_lab.test.file
+			name     = "test-node"
+			type     = "qemu"
+			template = "linux"
+		}
+	`, serverURL, name)
+}
+
+func TestEveLabRenameInPlace(t *testing.T) {
+	lab := &movableLab{}
+	server := setupMockEVEWithMovableLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: labConfigWithName(server.URL, "test-lab"),
+				Check:  resource.TestCheckResourceAttr("eve_node.test", "lab_file", "/test-lab.unl"),
+			},
+			{
+				Config: labConfigWithName(server.URL, "renamed-lab"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab.test", "id", "/renamed-lab.unl"),
+					resource.TestCheckResourceAttr("eve_lab.test", "file", "/renamed-lab.unl"),
+					resource.TestCheckResourceAttr("eve_node.test", "lab_file", "/renamed-lab.unl"),
+					resource.TestCheckResourceAttr("eve_node.test", "id", "/renamed-lab.unl:node:1"),
+					func(_ *terraform.State) error {
+						lab.mu.Lock()
+						defer lab.mu.Unlock()
+						if lab.moves != 1 || lab.labCreates != 1 || lab.nodeCreates != 1 {
+							return fmt.Errorf("expected a single move without replacement, got %d moves, %d lab creates, %d node creates",
+								lab.moves, lab.labCreates, lab.nodeCreates)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func lockedLabConfigWithName(serverURL, name, description string) string {
+	return fmt.Sprintf(`
+		provider "eve" {
+			endpoint = "%s"
+			username = "testuser"
+			password = "testpass"
+		}
+		resource "eve_lab" "test" {
+			path = "/"
+			name = %q
+			description = %q
+			lock = true
+		}
+	`, serverURL, name, description)
+}
+
+func TestEveLabUpdateStaysLocked(t *testing.T) {
+	lab := &movableLab{}
+	server := setupMockEVEWithMovableLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: lockedLabConfigWithName(server.URL, "test-lab", "test lab"),
+				Check:  resource.TestCheckResourceAttr("eve_lab.test", "lock", "true"),
+			},
+			{
+				// The lab is unlocked for the rename and the edit and locked again
+				Config: lockedLabConfigWithName(server.URL, "renamed-lab", "renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab.test", "id", "/renamed-lab.unl"),
+					resource.TestCheckResourceAttr("eve_lab.test", "description", "renamed"),
+					resource.TestCheckResourceAttr("eve_lab.test", "lock", "true"),
+					func(_ *terraform.State) error {
+						lab.mu.Lock()
+						defer lab.mu.Unlock()
+						if lab.moves != 1 || !lab.locked {
+							return fmt.Errorf("expected a single move leaving the lab locked, got %d moves, locked=%t", lab.moves, lab.locked)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestEveNodeMovedToAnotherLabIsReplaced(t *testing.T) {
+	lab := &movableLab{other: "/other-lab.unl"}
+	server := setupMockEVEWithMovableLab(lab)
+	defer server.Close()
+
+	nodeIn := func(labFile string) string {
+		return createTestConfig(server.URL, fmt.Sprintf(`
+			resource "eve_node" "test" {
+				lab_file = %s
+				name     = "test-node"
+				type     = "qemu"
+				template = "linux"
+			}
+		`, labFile))
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: nodeIn("eve_lab.test.file"),
+				Check:  resource.TestCheckResourceAttr("eve_node.test", "id", "/test-lab.unl:node:1"),
+			},
+			{
+				// Node 1 of another existing lab is a different node
+				Config: nodeIn(`"/other-lab.unl"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_node.test", "id", "/other-lab.unl:node:1"),
+					func(_ *terraform.State) error {
+						lab.mu.Lock()
+						defer lab.mu.Unlock()
+						if lab.nodeCreates != 2 {
+							return fmt.Errorf("expected the node to be replaced, got %d node creates", lab.nodeCreates)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestEveNodeMovedToNewLabIsReplaced(t *testing.T) {
+	lab := &movableLab{}
+	server := setupMockEVEWithMovableLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: createTestConfig(server.URL, `
+					resource "eve_node" "test" {
+						lab_file = eve_lab.test.file
+						name     = "test-node"
+						type     = "qemu"
+						template = "linux"
+					}
+				`),
+				Check: resource.TestCheckResourceAttr("eve_node.test", "id", "/test-lab.unl:node:1"),
+			},
+			{
+				// The lab_file names a lab created in the same apply, not a
+				// rename of the current one
+				Config: createTestConfig(server.URL, `
+					resource "eve_lab" "other" {
+						path = "/"
+						name = "other-lab"
+					}
+					resource "eve_node" "test" {
+						lab_file   = "/other-lab.unl"
+						name       = "test-node"
+						type       = "qemu"
+						template   = "linux"
+						depends_on = [eve_lab.other]
+					}
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_node.test", "id", "/other-lab.unl:node:1"),
+					func(_ *terraform.State) error {
+						lab.mu.Lock()
+						defer lab.mu.Unlock()
+						if lab.nodeCreates != 2 || lab.moves != 0 {
+							return fmt.Errorf("expected the node to be replaced, got %d node creates and %d moves", lab.nodeCreates, lab.moves)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
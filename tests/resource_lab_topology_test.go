@@ -0,0 +1,430 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// topologyLab is an in-memory lab whose nodes, networks and interface
+// wiring can be created, changed and deleted through the API
+type topologyLab struct {
+	mu       sync.Mutex
+	nextID   int
+	nodes    map[int]map[string]interface{}
+	networks map[int]map[string]interface{}
+	wiring   map[int]map[int]int
+	requests []string
+}
+
+func newTopologyLab() *topologyLab {
+	return &topologyLab{
+		nextID:   1,
+		nodes:    map[int]map[string]interface{}{},
+		networks: map[int]map[string]interface{}{},
+		wiring:   map[int]map[int]int{},
+	}
+}
+
+// addNode adds a node with two ethernet interfaces (e0, e1) to the lab
+func (l *topologyLab) addNode(name string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := l.nextID
+	l.nextID++
+	l.nodes[id] = map[string]interface{}{"id": id, "name": name, "type": "qemu", "template": "linux", "ethernet": 2}
+	return id
+}
+
+func (l *topologyLab) rewire(nodeID, ifIndex, networkID int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.wiring[nodeID] == nil {
+		l.wiring[nodeID] = map[int]int{}
+	}
+	l.wiring[nodeID][ifIndex] = networkID
+}
+
+// addNetwork adds a bridge network to the lab
+func (l *topologyLab) addNetwork(name string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := l.nextID
+	l.nextID++
+	l.networks[id] = map[string]interface{}{"id": id, "name": name, "type": "bridge", "visibility": "1"}
+	return id
+}
+
+// count returns how many requests matched the method and path prefix
+func (l *topologyLab) count(method, prefix string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, req := range l.requests {
+		if strings.HasPrefix(req, method+" "+prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (l *topologyLab) networkByName(name string) (int, map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, network := range l.networks {
+		if network["name"] == name {
+			return id, network
+		}
+	}
+	return 0, nil
+}
+
+func (l *topologyLab) nodeByName(name string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, node := range l.nodes {
+		if node["name"] == name {
+			return id
+		}
+	}
+	return 0
+}
+
+func (l *topologyLab) wiredTo(nodeID, ifIndex int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.wiring[nodeID][ifIndex]
+}
+
+func writeTopologyData(w http.ResponseWriter, data interface{}) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 200, "status": "success", "message": "ok", "data": data,
+	})
+}
+
+func (l *topologyLab) serveCollection(w http.ResponseWriter, r *http.Request, items map[int]map[string]interface{}, defaults map[string]interface{}) {
+	switch r.Method {
+	case interfaceHTTPMethodGET:
+		if len(items) == 0 {
+			writeTopologyData(w, []interface{}{})
+			return
+		}
+		listed := map[string]interface{}{}
+		for id, item := range items {
+			listed[strconv.Itoa(id)] = item
+		}
+		writeTopologyData(w, listed)
+	case interfaceHTTPMethodPOST:
+		item := map[string]interface{}{}
+		for k, v := range defaults {
+			item[k] = v
+		}
+		_ = json.NewDecoder(r.Body).Decode(&item)
+		id := l.nextID
+		l.nextID++
+		item["id"] = id
+		items[id] = item
+		writeTopologyData(w, map[string]interface{}{"id": id})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (l *topologyLab) serveItem(w http.ResponseWriter, r *http.Request, items map[int]map[string]interface{}, id int) {
+	item, ok := items[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code":404,"status":"fail","message":"Not found"}`)
+		return
+	}
+	switch r.Method {
+	case interfaceHTTPMethodGET:
+		writeTopologyData(w, item)
+	case interfaceHTTPMethodPUT:
+		_ = json.NewDecoder(r.Body).Decode(&item)
+		writeTopologyData(w, nil)
+	case interfaceHTTPMethodDELETE:
+		delete(items, id)
+		delete(l.wiring, id)
+		writeTopologyData(w, nil)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (l *topologyLab) serveInterfaces(w http.ResponseWriter, r *http.Request, nodeID int) {
+	if r.Method == interfaceHTTPMethodPUT {
+		var payload map[string]int
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if l.wiring[nodeID] == nil {
+			l.wiring[nodeID] = map[int]int{}
+		}
+		for idx, networkID := range payload {
+			i, _ := strconv.Atoi(idx)
+			l.wiring[nodeID][i] = networkID
+		}
+		writeTopologyData(w, nil)
+		return
+	}
+	count, _ := strconv.Atoi(fmt.Sprint(l.nodes[nodeID]["ethernet"]))
+	ethernet := make([]map[string]interface{}, count)
+	for i := range ethernet {
+		ethernet[i] = map[string]interface{}{"name": fmt.Sprintf("e%d", i), "network_id": l.wiring[nodeID][i]}
+	}
+	writeTopologyData(w, map[string]interface{}{"ethernet": ethernet, "serial": []interface{}{}, "id": nodeID, "sort": "qemu"})
+}
+
+func (l *topologyLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	rest := strings.TrimPrefix(r.URL.Path, "/api/labs/test-lab.unl/")
+	l.requests = append(l.requests, r.Method+" "+rest)
+	parts := strings.Split(rest, "/")
+
+	items, defaults := l.nodes, map[string]interface{}{
+		"image": "linux-default", "icon": "Server.png", "cpu": 1, "ram": 1024, "ethernet": 2, "serial": 0,
+		"top": 0, "left": 0, "status": 0,
+	}
+	if parts[0] == "networks" {
+		items, defaults = l.networks, map[string]interface{}{"type": "bridge", "top": 0, "left": 0, "visibility": "1", "count": 0}
+	} else if parts[0] != "nodes" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 1 {
+		l.serveCollection(w, r, items, defaults)
+		return
+	}
+	id, _ := strconv.Atoi(parts[1])
+	if len(parts) == 3 && parts[2] == "interfaces" {
+		l.serveInterfaces(w, r, id)
+		return
+	}
+	l.serveItem(w, r, items, id)
+}
+
+func setupMockEVEWithTopologyLab(lab *topologyLab) *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+	setupLabEndpoints(mux)
+	mux.Handle("/api/labs/test-lab.unl/", lab)
+	return httptest.NewServer(mux)
+}
+
+func topologyConfig(serverURL, body string) string {
+	return createTestConfig(serverURL, fmt.Sprintf(`
+		resource "eve_lab_topology" "test" {
+			lab_file = eve_lab.test.file
+			%s
+		}
+	`, body))
+}
+
+const topologyWithP2PLink = `
+	node {
+		name     = "r1"
+		type     = "qemu"
+		template = "linux"
+	}
+	node {
+		name     = "r2"
+		type     = "qemu"
+		template = "linux"
+		left     = 300
+	}
+	network {
+		name = "lan"
+	}
+	link {
+		a = "r1:e0"
+		b = "lan"
+	}
+	link {
+		a = "r1:e1"
+		b = "r2:e1"
+	}
+`
+
+const topologyWithoutR2 = `
+	node {
+		name     = "r1"
+		type     = "qemu"
+		template = "linux"
+	}
+	network {
+		name = "lan"
+	}
+	link {
+		a = "r1:e0"
+		b = "lan"
+	}
+`
+
+func TestEveLabTopologyCreatesAndPrunes(t *testing.T) {
+	lab := newTopologyLab()
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	var putsAfterCreate int
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: topologyConfig(server.URL, topologyWithP2PLink),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "id", "/test-lab.unl:topology"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.#", "2"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.0.image", "linux-default"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "link.#", "2"),
+					func(_ *terraform.State) error {
+						lanID, _ := lab.networkByName("lan")
+						bridgeID, bridge := lab.networkByName("link_r1_e1_r2_e1")
+						if bridge == nil {
+							return fmt.Errorf("expected a hidden network for the r1:e1 - r2:e1 link")
+						}
+						if bridge["visibility"] != "0" {
+							return fmt.Errorf("expected the link network to be hidden, got visibility %v", bridge["visibility"])
+						}
+						r1, r2 := lab.nodeByName("r1"), lab.nodeByName("r2")
+						if got := lab.wiredTo(r1, 0); got != lanID {
+							return fmt.Errorf("expected r1:e0 on network %d, got %d", lanID, got)
+						}
+						if lab.wiredTo(r1, 1) != bridgeID || lab.wiredTo(r2, 1) != bridgeID {
+							return fmt.Errorf("expected r1:e1 and r2:e1 on network %d", bridgeID)
+						}
+						putsAfterCreate = lab.count(interfaceHTTPMethodPUT, "nodes")
+						return nil
+					},
+				),
+			},
+			{
+				// Applying the same configuration again changes nothing
+				Config:   topologyConfig(server.URL, topologyWithP2PLink),
+				PlanOnly: true,
+			},
+			{
+				Config: topologyConfig(server.URL, topologyWithoutR2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.#", "1"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "link.#", "1"),
+					func(_ *terraform.State) error {
+						if lab.nodeByName("r2") != 0 {
+							return fmt.Errorf("expected r2 to be deleted")
+						}
+						if _, bridge := lab.networkByName("link_r1_e1_r2_e1"); bridge != nil {
+							return fmt.Errorf("expected the link network to be deleted")
+						}
+						if got := lab.wiredTo(lab.nodeByName("r1"), 1); got != 0 {
+							return fmt.Errorf("expected r1:e1 to be disconnected, got network %d", got)
+						}
+						// Only the interfaces of r1 are updated; the node itself is unchanged
+						if got := lab.count(interfaceHTTPMethodPUT, "nodes") - putsAfterCreate; got != 1 {
+							return fmt.Errorf("expected 1 update request, got %d", got)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestEveLabTopologyAppliesZeroValues(t *testing.T) {
+	lab := newTopologyLab()
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	leftAtZero := strings.Replace(topologyWithP2PLink, "left     = 300", "left     = 0", 1)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: topologyConfig(server.URL, topologyWithP2PLink),
+				Check:  resource.TestCheckResourceAttr("eve_lab_topology.test", "node.1.left", "300"),
+			},
+			{
+				// Moving a node back to the edge of the canvas is applied
+				Config: topologyConfig(server.URL, leftAtZero),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.1.left", "0"),
+					func(_ *terraform.State) error {
+						r2 := lab.nodeByName("r2")
+						lab.mu.Lock()
+						defer lab.mu.Unlock()
+						if left := lab.nodes[r2]["left"]; fmt.Sprint(left) != "0" {
+							return fmt.Errorf("expected r2 at left 0, got %v", left)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config:   topologyConfig(server.URL, leftAtZero),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestEveLabTopologyDetectsRemovedNode(t *testing.T) {
+	lab := newTopologyLab()
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: topologyConfig(server.URL, topologyWithP2PLink),
+			},
+			{
+				PreConfig: func() {
+					lab.mu.Lock()
+					defer lab.mu.Unlock()
+					for id, node := range lab.nodes {
+						if node["name"] == "r1" {
+							delete(lab.nodes, id)
+							delete(lab.wiring, id)
+						}
+					}
+				},
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				// The missing node keeps its place so that r2 stays at index 1
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.#", "2"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.0.name", "r1"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.0.id", "0"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.1.name", "r2"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.1.left", "300"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "link.#", "0"),
+				),
+			},
+			{
+				// Applying the configuration again recreates the node and its links
+				Config: topologyConfig(server.URL, topologyWithP2PLink),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "node.#", "2"),
+					resource.TestCheckResourceAttr("eve_lab_topology.test", "link.#", "2"),
+					func(_ *terraform.State) error {
+						if lab.nodeByName("r1") == 0 {
+							return fmt.Errorf("expected r1 to be recreated")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func linkConfig(serverURL string, nodeA, nodeB int) string {
+	return createTestConfig(serverURL, fmt.Sprintf(`
+		resource "eve_link" "test" {
+			lab_file    = eve_lab.test.file
+			a_node_id   = %d
+			a_interface = "e1"
+			b_node_id   = %d
+			b_interface = "e0"
+		}
+	`, nodeA, nodeB))
+}
+
+func TestEveLinkManagesBridgeNetwork(t *testing.T) {
+	lab := newTopologyLab()
+	r1, r2 := lab.addNode("r1"), lab.addNode("r2")
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	bridgeName := fmt.Sprintf("link_%d_1_%d_0", r1, r2)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		CheckDestroy: func(_ *terraform.State) error {
+			if _, network := lab.networkByName(bridgeName); network != nil {
+				return fmt.Errorf("expected the link network to be deleted with the link")
+			}
+			if lab.wiredTo(r1, 1) != 0 || lab.wiredTo(r2, 0) != 0 {
+				return fmt.Errorf("expected both ends to be disconnected")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: linkConfig(server.URL, r1, r2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_link.test", "network_name", bridgeName),
+					resource.TestCheckResourceAttr("eve_link.test", "a_interface_index", "1"),
+					resource.TestCheckResourceAttr("eve_link.test", "b_interface_index", "0"),
+					func(_ *terraform.State) error {
+						networkID, network := lab.networkByName(bridgeName)
+						if network == nil || network["visibility"] != "0" {
+							return fmt.Errorf("expected a hidden bridge network, got %v", network)
+						}
+						if lab.wiredTo(r1, 1) != networkID || lab.wiredTo(r2, 0) != networkID {
+							return fmt.Errorf("expected r1:e1 and r2:e0 on network %d", networkID)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "eve_link.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				PreConfig:          func() { lab.rewire(r2, 0, 0) },
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				Check:              resource.TestCheckResourceAttr("eve_link.test", "b_interface", ""),
+			},
+			{
+				// Applying the configuration again reconnects the rewired end
+				Config: linkConfig(server.URL, r1, r2),
+				Check: func(_ *terraform.State) error {
+					networkID, _ := lab.networkByName(bridgeName)
+					if got := lab.wiredTo(r2, 0); got != networkID {
+						return fmt.Errorf("expected r2:e0 reconnected to network %d, got %d", networkID, got)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 const (
@@ -180,3 +181,34 @@ func TestEveNetworkCreate(t *testing.T) {
 
 	runResourceTest(t, server, networkConfig, checks)
 }
+
+func TestEveNetworkImportByName(t *testing.T) {
+	lab := newTopologyLab()
+	lan := lab.addNetwork("lan")
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: createTestConfig(server.URL, `
+					resource "eve_network" "test" {
+						lab_file = eve_lab.test.file
+						name     = "lan"
+						type     = "bridge"
+					}
+				`),
+				ResourceName:  "eve_network.test",
+				ImportState:   true,
+				ImportStateId: "/test-lab.unl/lan",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].ID != fmt.Sprintf("/test-lab.unl:network:%d", lan) || states[0].Attributes["lab_file"] != "/test-lab.unl" {
+						return fmt.Errorf("unexpected imported network: %v", states)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
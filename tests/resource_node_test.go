@@ -1,12 +1,22 @@
 package tests
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 const (
@@ -205,3 +215,712 @@ func TestEveNodeCreate(t *testing.T) {
 
 	runResourceTest(t, server, nodeConfig, checks)
 }
+
+// powerNode is a single node whose runtime status can be changed as if it was
+// started, stopped or had crashed outside of Terraform. A started node stays
+// in the building state for bootReads reads before it reports as started,
+// and a hung node ignores graceful stops until it is wiped.
+type powerNode struct {
+	mu        sync.Mutex
+	status    int
+	updates   int
+	bootReads int
+	url       string
+	hung      bool
+	wipes     int
+	stops     int
+	payloads  []map[string]interface{}
+	config    string
+}
+
+func (n *powerNode) setStatus(status int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.status = status
+}
+
+func setupMockEVEWithPowerNode(node *powerNode) *httptest.Server {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+	setupLabEndpoints(mux)
+
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"code":201,"status":"success","message":"Node has been saved (60025).","data":{"id":1}}`)
+	})
+
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes/1", func(w http.ResponseWriter, r *http.Request) {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case nodeHTTPMethodGET:
+			if node.status == 1 {
+				if node.bootReads > 0 {
+					node.bootReads--
+				} else {
+					node.status = 2
+				}
+			}
+			fmt.Fprintf(w, `{
+				"code": 200,
+				"status": "success",
+				"message": "Node retrieved",
+				"data": {"name": "test-node", "type": "qemu", "template": "linux", "status": %d, "url": %q}
+			}`, node.status, node.url)
+		case "PUT":
+			node.updates++
+			var payload map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			node.payloads = append(node.payloads, payload)
+			fmt.Fprint(w, `{"code":201,"status":"success","message":"Lab has been saved (60023)."}`)
+		default:
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Node deleted"}`)
+		}
+	})
+
+	power := func(status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			node.setStatus(status)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Node started (80049)."}`)
+		}
+	}
+	mux.HandleFunc("/api/labs/test-lab.unl/configs/1", func(w http.ResponseWriter, r *http.Request) {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "PUT" {
+			var payload struct {
+				Data string `json:"data"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			node.config = payload.Data
+			fmt.Fprint(w, `{"code":201,"status":"success","message":"Lab has been saved (60023)."}`)
+			return
+		}
+		data, _ := json.Marshal(map[string]interface{}{"id": 1, "name": "test-node", "data": node.config})
+		fmt.Fprintf(w, `{"code":200,"status":"success","message":"Got config (60056).","data":%s}`, data)
+	})
+
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes/1/start", power(1))
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes/1/stop", func(w http.ResponseWriter, r *http.Request) {
+		node.mu.Lock()
+		node.stops++
+		hung := node.hung
+		node.mu.Unlock()
+		if hung {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Node stopped (80051)."}`)
+			return
+		}
+		power(0)(w, r)
+	})
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes/1/wipe", func(w http.ResponseWriter, r *http.Request) {
+		node.mu.Lock()
+		node.wipes++
+		node.mu.Unlock()
+		power(0)(w, r)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestEveNodePowerStateDrift(t *testing.T) {
+	node := &powerNode{}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	config := createTestConfig(server.URL, `resource "eve_node" "test" {
+		lab_file = eve_lab.test.file
+		name = "test-node"
+		type = "qemu"
+		template = "linux"
+		desired_state = "started"
+	}`)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("eve_node.test", "current_state", "started"),
+			},
+			{
+				// The node was stopped manually in the web UI
+				PreConfig:          func() { node.setStatus(0) },
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				Check:              resource.TestCheckResourceAttr("eve_node.test", "current_state", "stopped"),
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_node.test", "current_state", "started"),
+					func(_ *terraform.State) error {
+						node.mu.Lock()
+						defer node.mu.Unlock()
+						if node.updates != 0 {
+							return fmt.Errorf("expected power state to converge without updating the node, got %d updates", node.updates)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func waitForNodeConfig(serverURL, waitFor string) string {
+	return createTestConfig(serverURL, fmt.Sprintf(`resource "eve_node" "test" {
+		lab_file = eve_lab.test.file
+		name = "test-node"
+		type = "qemu"
+		template = "linux"
+		desired_state = "started"
+		wait_for {
+			%s
+			poll_interval = "10ms"
+		}
+	}`, waitFor))
+}
+
+func TestEveNodeWaitForStatus(t *testing.T) {
+	node := &powerNode{bootReads: 3}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: waitForNodeConfig(server.URL, `mode = "status"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_node.test", "current_state", "started"),
+					func(_ *terraform.State) error {
+						node.mu.Lock()
+						defer node.mu.Unlock()
+						if node.bootReads != 0 {
+							return fmt.Errorf("expected apply to wait for the node to boot, %d reads left", node.bootReads)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestEveNodeWaitForTimeout(t *testing.T) {
+	node := &powerNode{bootReads: 1 << 30}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      waitForNodeConfig(server.URL, `timeout = "1s"`),
+				ExpectError: regexp.MustCompile("did not become ready"),
+			},
+		},
+	})
+}
+
+func TestEveNodeWaitForConsoleRegex(t *testing.T) {
+	console, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer console.Close()
+	go func() {
+		for {
+			conn, err := console.Accept()
+			if err != nil {
+				return
+			}
+			fmt.Fprint(conn, "Booting kernel...\r\nUbuntu 22.04 LTS\r\ntest-node login: ")
+			conn.Close()
+		}
+	}()
+
+	node := &powerNode{url: "telnet://" + console.Addr().String()}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: waitForNodeConfig(server.URL, `
+					mode          = "console_regex"
+					console_regex = "login:\\s*$"
+				`),
+				Check: resource.TestCheckResourceAttr("eve_node.test", "wait_for.0.mode", "console_regex"),
+			},
+		},
+	})
+}
+
+func stopNodeConfig(serverURL, desiredState string, forceStop bool) string {
+	return createTestConfig(serverURL, fmt.Sprintf(`resource "eve_node" "test" {
+		lab_file = eve_lab.test.file
+		name = "test-node"
+		type = "qemu"
+		template = "linux"
+		desired_state = %q
+		stop_timeout = "1s"
+		force_stop = %t
+	}`, desiredState, forceStop))
+}
+
+func TestEveNodeStopTimeoutIsReported(t *testing.T) {
+	node := &powerNode{hung: true}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: stopNodeConfig(server.URL, "started", false),
+				Check:  resource.TestCheckResourceAttr("eve_node.test", "current_state", "started"),
+			},
+			{
+				Config:      stopNodeConfig(server.URL, "stopped", false),
+				ExpectError: regexp.MustCompile("did not stop within 1s"),
+			},
+		},
+	})
+}
+
+func TestEveNodeForceStopWipesHungNode(t *testing.T) {
+	node := &powerNode{hung: true}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: stopNodeConfig(server.URL, "started", true),
+			},
+			{
+				Config: stopNodeConfig(server.URL, "stopped", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_node.test", "current_state", "stopped"),
+					func(_ *terraform.State) error {
+						node.mu.Lock()
+						defer node.mu.Unlock()
+						if node.wipes != 1 {
+							return fmt.Errorf("expected the hung node to be wiped once, got %d wipes", node.wipes)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func rebootingNodeConfig(serverURL string, left, ram int) string {
+	return createTestConfig(serverURL, fmt.Sprintf(`resource "eve_node" "test" {
+		lab_file = eve_lab.test.file
+		name = "test-node"
+		type = "qemu"
+		template = "linux"
+		left = %d
+		ram = %d
+		desired_state = "started"
+		reboot_on_change = true
+	}`, left, ram))
+}
+
+// lastUpdate checks the most recent node update payload and the number of
+// times the node was stopped so far
+func (n *powerNode) lastUpdate(wantKeys []string, wantStops int) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		if len(n.payloads) == 0 {
+			return fmt.Errorf("expected the node to be updated")
+		}
+		payload := n.payloads[len(n.payloads)-1]
+		if len(payload) != len(wantKeys) {
+			return fmt.Errorf("expected update payload with keys %v, got %v", wantKeys, payload)
+		}
+		for _, k := range wantKeys {
+			if _, ok := payload[k]; !ok {
+				return fmt.Errorf("expected update payload with keys %v, got %v", wantKeys, payload)
+			}
+		}
+		if n.stops != wantStops {
+			return fmt.Errorf("expected %d stops, got %d", wantStops, n.stops)
+		}
+		return nil
+	}
+}
+
+func TestEveNodeUpdateSendsOnlyChangedFields(t *testing.T) {
+	node := &powerNode{}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: rebootingNodeConfig(server.URL, 100, 1024),
+			},
+			{
+				// Moving the node on the canvas is applied live
+				Config: rebootingNodeConfig(server.URL, 300, 1024),
+				Check:  node.lastUpdate([]string{"id", "left"}, 0),
+			},
+			{
+				// Memory only applies on boot, so the running node is restarted
+				Config: rebootingNodeConfig(server.URL, 300, 2048),
+				Check: resource.ComposeTestCheckFunc(
+					node.lastUpdate([]string{"id", "ram"}, 1),
+					resource.TestCheckResourceAttr("eve_node.test", "current_state", "started"),
+				),
+			},
+		},
+	})
+}
+
+func startupConfigNodeConfig(serverURL, configAttr string) string {
+	return createTestConfig(serverURL, fmt.Sprintf(`resource "eve_node" "test" {
+		lab_file = eve_lab.test.file
+		name = "test-node"
+		type = "qemu"
+		template = "linux"
+		%s
+	}`, configAttr))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestEveNodeStartupConfig(t *testing.T) {
+	node := &powerNode{}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	const startup = "hostname r1\ninterface e0/0\n ip address 10.0.0.1 255.255.255.0\n"
+	config := startupConfigNodeConfig(server.URL, `startup_config = "hostname r1\ninterface e0/0\n ip address 10.0.0.1 255.255.255.0\n"`)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					// Only the hash of the configuration is kept in state
+					resource.TestCheckResourceAttr("eve_node.test", "startup_config", sha256Hex(startup)),
+					resource.TestCheckResourceAttr("eve_node.test", "startup_config_hash", sha256Hex(startup)),
+					func(_ *terraform.State) error {
+						node.mu.Lock()
+						defer node.mu.Unlock()
+						if node.config != startup {
+							return fmt.Errorf("expected startup config to be uploaded, got %q", node.config)
+						}
+						last := node.payloads[len(node.payloads)-1]
+						if last["config"] != "1" {
+							return fmt.Errorf("expected node config flag to be enabled, got %v", last)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// Someone edited the startup config in the web UI
+				PreConfig: func() {
+					node.mu.Lock()
+					defer node.mu.Unlock()
+					node.config = "hostname changed\n"
+				},
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				Check:              resource.TestCheckResourceAttr("eve_node.test", "startup_config_hash", sha256Hex("hostname changed\n")),
+			},
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("eve_node.test", "startup_config_hash", sha256Hex(startup)),
+			},
+			{
+				// Removing the startup config boots the node without it
+				Config: startupConfigNodeConfig(server.URL, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_node.test", "startup_config_hash", ""),
+					func(_ *terraform.State) error {
+						node.mu.Lock()
+						defer node.mu.Unlock()
+						last := node.payloads[len(node.payloads)-1]
+						if last["config"] != "0" {
+							return fmt.Errorf("expected node config flag to be disabled, got %v", last)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestEveNodeStartupConfigFile(t *testing.T) {
+	node := &powerNode{}
+	server := setupMockEVEWithPowerNode(node)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "r1.cfg")
+	if err := os.WriteFile(path, []byte("hostname r1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config := startupConfigNodeConfig(server.URL, fmt.Sprintf("startup_config_file = %q", path))
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("eve_node.test", "startup_config_hash", sha256Hex("hostname r1\n")),
+			},
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(path, []byte("hostname r1-new\n"), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_node.test", "startup_config_hash", sha256Hex("hostname r1-new\n")),
+					func(_ *terraform.State) error {
+						node.mu.Lock()
+						defer node.mu.Unlock()
+						if node.config != "hostname r1-new\n" {
+							return fmt.Errorf("expected edited startup config file to be uploaded, got %q", node.config)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// setupTemplateCatalogEndpoints serves a catalog with a qemu "linux"
+// template that has two images and cpu, ram and console defaults, and an
+// "iol" template
+func setupTemplateCatalogEndpoints(mux *http.ServeMux) {
+	mux.HandleFunc("/api/list/templates/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch strings.TrimPrefix(r.URL.Path, "/api/list/templates/") {
+		case "":
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Listed templates","data":{"linux":"Linux","iol":"Cisco IOL"}}`)
+		case "linux":
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Template loaded","data":{
+				"type": "qemu",
+				"description": "Linux",
+				"options": {
+					"image": {"name": "Image", "type": "list", "list": {"linux-debian-12": "linux-debian-12", "linux-ubuntu-22.04": "linux-ubuntu-22.04"}, "value": "linux-ubuntu-22.04"},
+					"cpu": {"name": "CPU", "type": "input", "value": "1"},
+					"ram": {"name": "RAM (MB)", "type": "input", "value": "1024"},
+					"console": {"name": "Console", "type": "list", "list": {"telnet": "telnet", "vnc": "vnc"}, "value": "telnet"}
+				}
+			}}`)
+		case "iol":
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Template loaded","data":{"type":"iol","description":"Cisco IOL","options":{"image":{"name":"Image","type":"list","list":[],"value":""}}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code":404,"status":"fail","message":"Template not found"}`)
+		}
+	})
+}
+
+func catalogNodeConfig(serverURL, nodeType, template, image string) string {
+	return createTestConfig(serverURL, fmt.Sprintf(`
+		resource "eve_node" "first" {
+			lab_file = eve_lab.test.file
+			name     = "first"
+			type     = %[1]q
+			template = %[2]q
+			image    = %[3]q
+		}
+		resource "eve_node" "second" {
+			lab_file = eve_lab.test.file
+			name     = "second"
+			type     = %[1]q
+			template = %[2]q
+			image    = %[3]q
+		}
+	`, nodeType, template, image))
+}
+
+func TestEveNodeTemplateValidatedAtPlan(t *testing.T) {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+	setupLabEndpoints(mux)
+	setupTemplateCatalogEndpoints(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      catalogNodeConfig(server.URL, "qemu", "linx", "linux-ubuntu-22.04"),
+				ExpectError: regexp.MustCompile(`unknown template "linx"`),
+			},
+			{
+				Config:      catalogNodeConfig(server.URL, "iol", "linux", "linux-ubuntu-22.04"),
+				ExpectError: regexp.MustCompile(`template "linux" is a qemu template, but type is "iol"`),
+			},
+			{
+				Config:      catalogNodeConfig(server.URL, "qemu", "linux", "linux-ubuntu-20.04"),
+				ExpectError: regexp.MustCompile(`image "linux-ubuntu-20.04" is not available for template "linux" \(available: linux-debian-12, linux-ubuntu-22.04\)`),
+			},
+			{
+				// A valid node passes validation; the plan still creates it
+				Config:             catalogNodeConfig(server.URL, "qemu", "linux", "linux-ubuntu-22.04"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// setupTemplateDefaultsNode serves node 1, which is created and read back
+// with the payload the provider sent
+func setupTemplateDefaultsNode(mux *http.ServeMux) *sync.Map {
+	var node sync.Map
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		for k, v := range payload {
+			node.Store(k, v)
+		}
+		fmt.Fprint(w, `{"code":201,"status":"success","message":"Node created","data":{"id":1}}`)
+	})
+	mux.HandleFunc("/api/labs/test-lab.unl/nodes/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != nodeHTTPMethodGET {
+			fmt.Fprint(w, `{"code":200,"status":"success","message":"Node updated"}`)
+			return
+		}
+		data := map[string]interface{}{"status": 0}
+		node.Range(func(k, v interface{}) bool {
+			data[k.(string)] = v
+			return true
+		})
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "status": "success", "message": "Node retrieved", "data": data})
+	})
+	return &node
+}
+
+func TestEveNodeTemplateDefaults(t *testing.T) {
+	mux := http.NewServeMux()
+	setupLoginEndpoint(mux)
+	setupLabEndpoints(mux)
+	setupTemplateCatalogEndpoints(mux)
+	node := setupTemplateDefaultsNode(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: createTestConfig(server.URL, `
+					resource "eve_node" "test" {
+						lab_file = eve_lab.test.file
+						name     = "test-node"
+						type     = "qemu"
+						template = "linux"
+						ram      = 2048
+					}
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("eve_node.test", "cpu", "1"),
+					resource.TestCheckResourceAttr("eve_node.test", "ram", "2048"),
+					resource.TestCheckResourceAttr("eve_node.test", "console", "telnet"),
+					func(_ *terraform.State) error {
+						if cpu, _ := node.Load("cpu"); fmt.Sprint(cpu) != "1" {
+							return fmt.Errorf("expected the template's cpu default in the create payload, got %v", cpu)
+						}
+						if ram, _ := node.Load("ram"); fmt.Sprint(ram) != "2048" {
+							return fmt.Errorf("expected the configured ram in the create payload, got %v", ram)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestEveNodeImportByName(t *testing.T) {
+	lab := newTopologyLab()
+	lab.addNode("r1")
+	r2 := lab.addNode("r2")
+	lab.addNode("dup")
+	lab.addNode("dup")
+	clash := lab.addNode(fmt.Sprint(r2))
+	lab.nodes[r2]["status"] = 2
+	server := setupMockEVEWithTopologyLab(lab)
+	defer server.Close()
+
+	config := createTestConfig(server.URL, `
+		resource "eve_node" "test" {
+			lab_file = eve_lab.test.file
+			name     = "r2"
+			type     = "qemu"
+			template = "linux"
+		}
+	`)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: getProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:        config,
+				ResourceName:  "eve_node.test",
+				ImportState:   true,
+				ImportStateId: "/test-lab.unl/r2",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected one imported node, got %d", len(states))
+					}
+					attrs := states[0].Attributes
+					if states[0].ID != fmt.Sprintf("/test-lab.unl:node:%d", r2) || attrs["lab_file"] != "/test-lab.unl" || attrs["name"] != "r2" {
+						return fmt.Errorf("unexpected imported node %s: %v", states[0].ID, attrs)
+					}
+					if attrs["desired_state"] != "started" {
+						return fmt.Errorf("expected a running node to be imported as started, got %q", attrs["desired_state"])
+					}
+					return nil
+				},
+			},
+			{
+				Config:        config,
+				ResourceName:  "eve_node.test",
+				ImportState:   true,
+				ImportStateId: "/test-lab.unl/dup",
+				ExpectError:   regexp.MustCompile(`lab /test-lab.unl has 2 nodes matching "dup" by name or ID \(IDs 3, 4\)`),
+			},
+			{
+				// Node 5 is named after node 2
+				Config:        config,
+				ResourceName:  "eve_node.test",
+				ImportState:   true,
+				ImportStateId: fmt.Sprintf("/test-lab.unl/%d", r2),
+				ExpectError:   regexp.MustCompile(fmt.Sprintf(`2 nodes matching "%d" by name or ID \(IDs %d, %d\); import one of them by ID instead, e.g. /test-lab.unl:node:%d`, r2, r2, clash, r2)),
+			},
+		},
+	})
+}